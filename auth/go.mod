@@ -0,0 +1,17 @@
+module github.com/go-4devs/httpclient/auth
+
+go 1.12
+
+replace (
+	github.com/go-4devs/httpclient => ../
+	github.com/go-4devs/httpclient/apierrors => ../apierrors
+	github.com/go-4devs/httpclient/dc => ../dc
+	github.com/go-4devs/httpclient/decoder => ../decoder
+	github.com/go-4devs/httpclient/transport => ../transport
+)
+
+require (
+	github.com/go-4devs/httpclient/dc v0.0.2
+	github.com/go-4devs/httpclient/transport v0.0.2
+	github.com/stretchr/testify v1.4.0
+)
@@ -0,0 +1,208 @@
+package oauth2
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// ErrKey the private key is not supported by the algorithm
+var ErrKey = errors.New("oauth2: unsupported private key")
+
+// AssertionType of the jwt client assertion
+const AssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// Algorithms of the jwt signature
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// JWTSigner sign the jwt assertion
+type JWTSigner interface {
+	Algorithm() string
+	Sign(data []byte) ([]byte, error)
+}
+
+var (
+	_ JWTSigner = &rs256{}
+	_ JWTSigner = &es256{}
+)
+
+// NewRS256 create signer by the rsa private key
+func NewRS256(key *rsa.PrivateKey) JWTSigner {
+	return &rs256{key: key}
+}
+
+type rs256 struct {
+	key *rsa.PrivateKey
+}
+
+func (*rs256) Algorithm() string {
+	return AlgorithmRS256
+}
+
+func (s *rs256) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+
+	return rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+}
+
+// NewES256 create signer by the P-256 private key
+func NewES256(key *ecdsa.PrivateKey) JWTSigner {
+	return &es256{key: key}
+}
+
+type es256 struct {
+	key *ecdsa.PrivateKey
+}
+
+func (*es256) Algorithm() string {
+	return AlgorithmES256
+}
+
+func (s *es256) Sign(data []byte) ([]byte, error) {
+	if s.key.Curve != elliptic.P256() {
+		return nil, ErrKey
+	}
+	digest := sha256.Sum256(data)
+	r, ss, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 64)
+	fillBytes(sig[:32], r)
+	fillBytes(sig[32:], ss)
+
+	return sig, nil
+}
+
+// fillBytes set the absolute value of the number to the buffer as a zero-extended big-endian
+func fillBytes(buf []byte, n *big.Int) {
+	b := n.Bytes()
+	for i := range buf[:len(buf)-len(b)] {
+		buf[i] = 0
+	}
+	copy(buf[len(buf)-len(b):], b)
+}
+
+type assertionConfig struct {
+	keyID    string
+	audience string
+	lifetime time.Duration
+	claims   map[string]interface{}
+	source   []Option
+}
+
+// AssertionOption configure jwt assertion source
+type AssertionOption func(c *assertionConfig)
+
+// WithSourceOptions set options of the token source like scopes or http client
+func WithSourceOptions(opts ...Option) AssertionOption {
+	return func(c *assertionConfig) {
+		c.source = append(c.source, opts...)
+	}
+}
+
+// WithKeyID set kid header of the jwt assertion
+func WithKeyID(keyID string) AssertionOption {
+	return func(c *assertionConfig) {
+		c.keyID = keyID
+	}
+}
+
+// WithAudience set aud claim of the jwt assertion, by default the token url
+func WithAudience(audience string) AssertionOption {
+	return func(c *assertionConfig) {
+		c.audience = audience
+	}
+}
+
+// WithAssertionLifetime set lifetime of the jwt assertion, by default 5 minutes
+func WithAssertionLifetime(d time.Duration) AssertionOption {
+	return func(c *assertionConfig) {
+		c.lifetime = d
+	}
+}
+
+// WithClaim set claim of the jwt assertion, the claim replace default one like sub
+func WithClaim(name string, value interface{}) AssertionOption {
+	return func(c *assertionConfig) {
+		c.claims[name] = value
+	}
+}
+
+// NewJWTAssertion create source of the client credentials grant authenticated by the signed jwt (private_key_jwt)
+// the assertion issued by the client id for the token url and signed on each token request
+func NewJWTAssertion(tokenURL, clientID string, signer JWTSigner, opts ...AssertionOption) (*Source, error) {
+	cfg := assertionConfig{
+		audience: tokenURL,
+		lifetime: 5 * time.Minute,
+		claims:   make(map[string]interface{}),
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	s, err := newSource(tokenURL, clientID, "", "", cfg.source...)
+	if err != nil {
+		return nil, err
+	}
+	s.assertion = func(now time.Time) (string, error) {
+		jti := make([]byte, 16)
+		if _, err := rand.Read(jti); err != nil {
+			return "", err
+		}
+		claims := map[string]interface{}{
+			"iss": clientID,
+			"sub": clientID,
+			"aud": cfg.audience,
+			"iat": now.Unix(),
+			"exp": now.Add(cfg.lifetime).Unix(),
+			"jti": hex.EncodeToString(jti),
+		}
+		for name, value := range cfg.claims {
+			claims[name] = value
+		}
+
+		return signJWT(signer, cfg.keyID, claims)
+	}
+
+	return s, nil
+}
+
+func signJWT(signer JWTSigner, keyID string, claims map[string]interface{}) (string, error) {
+	header := map[string]string{
+		"alg": signer.Algorithm(),
+		"typ": "JWT",
+	}
+	if keyID != "" {
+		header["kid"] = keyID
+	}
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	data := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	sig, err := signer.Sign([]byte(data))
+	if err != nil {
+		return "", err
+	}
+
+	return data + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
@@ -0,0 +1,29 @@
+//go:build go1.13
+// +build go1.13
+
+package oauth2
+
+import (
+	"crypto/ed25519"
+)
+
+var _ JWTSigner = eddsa(nil)
+
+// NewEdDSA create signer by the ed25519 private key
+func NewEdDSA(key ed25519.PrivateKey) JWTSigner {
+	return eddsa(key)
+}
+
+type eddsa ed25519.PrivateKey
+
+func (eddsa) Algorithm() string {
+	return AlgorithmEdDSA
+}
+
+func (k eddsa) Sign(data []byte) ([]byte, error) {
+	if len(k) != ed25519.PrivateKeySize {
+		return nil, ErrKey
+	}
+
+	return ed25519.Sign(ed25519.PrivateKey(k), data), nil
+}
@@ -0,0 +1,25 @@
+//go:build go1.13
+// +build go1.13
+
+package oauth2
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+)
+
+func init() {
+	testSigners = append(testSigners, func() (JWTSigner, verifyKey, error) {
+		pub, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewEdDSA(key), func(data, sig []byte) error {
+			if !ed25519.Verify(pub, data, sig) {
+				return errors.New("invalid signature")
+			}
+			return nil
+		}, nil
+	})
+}
@@ -0,0 +1,168 @@
+package oauth2
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/dc"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNewJWTAssertion() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := NewJWTAssertion("https://auth.example.com/oauth2/token", "client", NewRS256(key),
+		WithKeyID("key-1"),
+		WithSourceOptions(WithScopes("read")),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cl := dc.Must("https://api.example.com", dc.WithMiddleware(New(src)))
+	req, _ := http.NewRequest(http.MethodGet, "/user/1", nil)
+	var user struct {
+		ID   int
+		Name string
+	}
+	if err := cl.Do(req, &user); err != nil {
+		log.Fatal(err)
+	}
+	log.Print(user)
+}
+
+// verifyKey check signature of the data by the public key
+type verifyKey func(data, sig []byte) error
+
+// testSigners create signers with the verify keys of the supported algorithms
+var testSigners = []func() (JWTSigner, verifyKey, error){
+	func() (JWTSigner, verifyKey, error) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewRS256(key), func(data, sig []byte) error {
+			digest := sha256.Sum256(data)
+			return rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig)
+		}, nil
+	},
+	func() (JWTSigner, verifyKey, error) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewES256(key), func(data, sig []byte) error {
+			digest := sha256.Sum256(data)
+			if len(sig) != 64 || !ecdsa.Verify(&key.PublicKey, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+				return errors.New("invalid signature")
+			}
+			return nil
+		}, nil
+	},
+}
+
+// verifyJWT check signature of the assertion and get header and claims
+func verifyJWT(token string, verify verifyKey) (map[string]interface{}, map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("malformed")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = verify([]byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, nil, err
+	}
+
+	var header, claims map[string]interface{}
+	for i, v := range []*map[string]interface{}{&header, &claims} {
+		b, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(b, v); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return header, claims, nil
+}
+
+func TestNewJWTAssertion(t *testing.T) {
+	for _, create := range testSigners {
+		signer, verify, err := create()
+		require.Nil(t, err)
+		t.Run(signer.Algorithm(), func(t *testing.T) {
+			now := time.Unix(1600000000, 0)
+			var s *httptest.Server
+			s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Nil(t, r.ParseForm())
+				_, _, basic := r.BasicAuth()
+				require.False(t, basic)
+				require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+				require.Equal(t, "client", r.PostForm.Get("client_id"))
+				require.Equal(t, AssertionType, r.PostForm.Get("client_assertion_type"))
+				require.Equal(t, "read", r.PostForm.Get("scope"))
+
+				header, claims, err := verifyJWT(r.PostForm.Get("client_assertion"), verify)
+				require.Nil(t, err)
+				require.Equal(t, map[string]interface{}{"alg": signer.Algorithm(), "typ": "JWT", "kid": "key-1"}, header)
+				require.Equal(t, "client", claims["iss"])
+				require.Equal(t, "service", claims["sub"])
+				require.Equal(t, s.URL+"/token", claims["aud"])
+				require.Equal(t, float64(now.Unix()), claims["iat"])
+				require.Equal(t, float64(now.Add(time.Minute).Unix()), claims["exp"])
+				require.Len(t, claims["jti"], 32)
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+			}))
+			defer s.Close()
+
+			src, err := NewJWTAssertion(s.URL+"/token", "client", signer,
+				WithKeyID("key-1"),
+				WithClaim("sub", "service"),
+				WithAssertionLifetime(time.Minute),
+				WithSourceOptions(WithScopes("read")),
+			)
+			require.Nil(t, err)
+			src.cfg.now = func() time.Time {
+				return now
+			}
+
+			tok, err := src.Token(context.Background())
+			require.Nil(t, err)
+			require.Equal(t, "token", tok.AccessToken)
+			cached, err := src.Token(context.Background())
+			require.Nil(t, err)
+			require.Equal(t, tok, cached)
+		})
+	}
+}
+
+func TestNewJWTAssertion_Key(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.Nil(t, err)
+	src, err := NewJWTAssertion("http://127.0.0.1/token", "client", NewES256(key))
+	require.Nil(t, err)
+	_, err = src.Token(context.Background())
+	require.Equal(t, ErrKey, err)
+}
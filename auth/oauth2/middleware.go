@@ -0,0 +1,48 @@
+package oauth2
+
+import (
+	"net/http"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+// New create middleware which authorize requests by the token of the source
+// on unauthorized response the token of the invalidator source dropped and the request retried once with a fresh token
+// when the body of the request can be replayed
+func New(src TokenSource) transport.Middleware {
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		t, err := src.Token(r.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := n(authorize(r, t))
+		if err != nil || res.StatusCode != http.StatusUnauthorized {
+			return res, err
+		}
+		inv, ok := src.(Invalidator)
+		if !ok || !transport.Replayable(r) {
+			return res, nil
+		}
+
+		inv.Invalidate(t)
+		fresh, err := src.Token(r.Context())
+		if err != nil || fresh.AccessToken == t.AccessToken {
+			return res, nil
+		}
+		replay, err := transport.Replay(r)
+		if err != nil {
+			return res, nil
+		}
+		transport.Drain(res.Body)
+
+		return n(authorize(replay, fresh))
+	}
+}
+
+func authorize(r *http.Request, t *Token) *http.Request {
+	ar := transport.CloneRequest(r)
+	ar.Header.Set("Authorization", t.Type()+" "+t.AccessToken)
+
+	return ar
+}
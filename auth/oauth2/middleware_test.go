@@ -0,0 +1,232 @@
+package oauth2
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/dc"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	src, err := NewClientCredentials("https://auth.example.com/oauth2/token", "client", "secret",
+		WithScopes("read", "write"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cl := dc.Must("https://api.example.com", dc.WithMiddleware(New(src)))
+	req, _ := http.NewRequest(http.MethodGet, "/user/1", nil)
+	var user struct {
+		ID   int
+		Name string
+	}
+	if err := cl.Do(req, &user); err != nil {
+		log.Fatal(err)
+	}
+	log.Print(user)
+}
+
+type tokenServer struct {
+	count int32
+	delay time.Duration
+	forms []string
+	mu    sync.Mutex
+}
+
+func (ts *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&ts.count, 1)
+	time.Sleep(ts.delay)
+	_ = r.ParseForm()
+	id, secret, _ := r.BasicAuth()
+	id, _ = url.QueryUnescape(id)
+	secret, _ = url.QueryUnescape(secret)
+	ts.mu.Lock()
+	ts.forms = append(ts.forms, r.PostForm.Encode())
+	ts.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if id != "client" || secret != "s&cret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"client authentication failed"}`))
+		return
+	}
+	_, _ = w.Write([]byte(`{"access_token":"token` + strconv.Itoa(int(n)) +
+		`","token_type":"bearer","expires_in":3600,"refresh_token":"refresh` + strconv.Itoa(int(n)) + `"}`))
+}
+
+func TestSource_Token(t *testing.T) {
+	ts := &tokenServer{delay: 20 * time.Millisecond}
+	s := httptest.NewServer(ts)
+	defer s.Close()
+
+	src, err := NewClientCredentials(s.URL+"/token", "client", "s&cret", WithScopes("read", "write"), WithParam("audience", "api"))
+	require.Nil(t, err)
+
+	var wg sync.WaitGroup
+	tokens := make([]*Token, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tok, err := src.Token(context.Background())
+			require.Nil(t, err)
+			tokens[i] = tok
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&ts.count))
+	for _, tok := range tokens {
+		require.Equal(t, "token1", tok.AccessToken)
+		require.Equal(t, "Bearer", tok.Type())
+	}
+	require.Equal(t, []string{"audience=api&grant_type=client_credentials&scope=read+write"}, ts.forms)
+
+	tok, err := src.Token(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, tokens[0], tok)
+
+	src.cfg.now = func() time.Time {
+		return time.Now().Add(time.Hour - 5*time.Second)
+	}
+	tok, err = src.Token(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "token2", tok.AccessToken)
+	require.Equal(t, "audience=api&grant_type=client_credentials&scope=read+write", ts.forms[1])
+}
+
+func TestNewRefreshToken(t *testing.T) {
+	ts := &tokenServer{}
+	s := httptest.NewServer(ts)
+	defer s.Close()
+
+	src, err := NewRefreshToken(s.URL, "client", "s&cret", "refresh0")
+	require.Nil(t, err)
+	tok, err := src.Token(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "token1", tok.AccessToken)
+
+	src.Invalidate(tok)
+	tok, err = src.Token(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "token2", tok.AccessToken)
+	require.Equal(t, []string{
+		"grant_type=refresh_token&refresh_token=refresh0",
+		"grant_type=refresh_token&refresh_token=refresh1",
+	}, ts.forms)
+}
+
+func TestSource_Error(t *testing.T) {
+	ts := &tokenServer{}
+	s := httptest.NewServer(ts)
+	defer s.Close()
+
+	src, err := NewClientCredentials(s.URL, "client", "invalid", WithCredentialsInBody())
+	require.Nil(t, err)
+	_, err = src.Token(context.Background())
+	require.Equal(t, &Error{
+		Code:        "invalid_client",
+		Description: "client authentication failed",
+		StatusCode:  http.StatusUnauthorized,
+	}, err)
+	require.EqualError(t, err, "oauth2: token endpoint status Unauthorized, error invalid_client: client authentication failed")
+	require.Equal(t, []string{"client_id=client&client_secret=invalid&grant_type=client_credentials"}, ts.forms)
+}
+
+func TestSource_Canceled(t *testing.T) {
+	ts := &tokenServer{delay: 50 * time.Millisecond}
+	s := httptest.NewServer(ts)
+	defer s.Close()
+
+	src, err := NewClientCredentials(s.URL, "client", "s&cret")
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		_, err := src.Token(ctx)
+		done <- err
+	}()
+	time.Sleep(time.Millisecond)
+
+	tok, err := src.Token(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "token2", tok.AccessToken)
+	require.NotNil(t, <-done)
+}
+
+func TestNew(t *testing.T) {
+	ts := &tokenServer{}
+	s := httptest.NewServer(ts)
+	defer s.Close()
+
+	src, err := NewClientCredentials(s.URL, "client", "s&cret")
+	require.Nil(t, err)
+	mw := New(src)
+
+	var auth []string
+	var bodies []string
+	next := func(r *http.Request) (*http.Response, error) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		status := http.StatusOK
+		if len(auth) == 1 {
+			status = http.StatusUnauthorized
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+		}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "http://api.example.com/user", strings.NewReader("name=user"))
+	require.Nil(t, err)
+	res, err := mw(req, next)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, []string{"Bearer token1", "Bearer token2"}, auth)
+	require.Equal(t, []string{"name=user", "name=user"}, bodies)
+	require.Empty(t, req.Header.Get("Authorization"))
+
+	res, err = mw(req, next)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "Bearer token2", auth[2])
+	require.Equal(t, int32(2), atomic.LoadInt32(&ts.count))
+}
+
+type staticSource Token
+
+func (s *staticSource) Token(ctx context.Context) (*Token, error) {
+	return (*Token)(s), nil
+}
+
+func TestNew_Unauthorized(t *testing.T) {
+	mw := New(&staticSource{AccessToken: "static", TokenType: "MAC"})
+	var count int
+	req, err := http.NewRequest(http.MethodGet, "http://api.example.com/user", nil)
+	require.Nil(t, err)
+	res, err := mw(req, func(r *http.Request) (*http.Response, error) {
+		count++
+		require.Equal(t, "MAC static", r.Header.Get("Authorization"))
+		return &http.Response{StatusCode: http.StatusUnauthorized}, nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,301 @@
+// Package oauth2 authorize requests by the tokens of the client credentials, refresh token and jwt assertion grants
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient/dc"
+)
+
+// Token of the token endpoint
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Scope        string `json:"scope"`
+	// Expiry time of the access token, zero when token not expire
+	Expiry time.Time `json:"-"`
+}
+
+// Type get type of the token for the authorization header, by default Bearer
+func (t *Token) Type() string {
+	if t.TokenType == "" || strings.EqualFold(t.TokenType, "bearer") {
+		return "Bearer"
+	}
+
+	return t.TokenType
+}
+
+// Valid check access token is set and not expired with the delta
+func (t *Token) Valid(now time.Time, delta time.Duration) bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || now.Add(delta).Before(t.Expiry))
+}
+
+// Error response of the token endpoint
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+	URI         string `json:"error_uri"`
+	StatusCode  int    `json:"-"`
+}
+
+func (e *Error) Error() string {
+	msg := "oauth2: token endpoint status " + http.StatusText(e.StatusCode)
+	if e.Code != "" {
+		msg += ", error " + e.Code
+	}
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+
+	return msg
+}
+
+// TokenSource get tokens
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// Invalidator invalidate token rejected by the server
+type Invalidator interface {
+	Invalidate(t *Token)
+}
+
+var (
+	_ TokenSource = &Source{}
+	_ Invalidator = &Source{}
+)
+
+type config struct {
+	httpClient  *http.Client
+	scopes      []string
+	params      url.Values
+	expiryDelta time.Duration
+	basicAuth   bool
+	now         func() time.Time
+}
+
+// Option configure source
+type Option func(c *config)
+
+// WithHTTPClient set http client of the token endpoint
+func WithHTTPClient(cl *http.Client) Option {
+	return func(c *config) {
+		c.httpClient = cl
+	}
+}
+
+// WithScopes set scopes of the requested token
+func WithScopes(scopes ...string) Option {
+	return func(c *config) {
+		c.scopes = scopes
+	}
+}
+
+// WithParam add parameter to the token request like audience
+func WithParam(name, value string) Option {
+	return func(c *config) {
+		c.params.Add(name, value)
+	}
+}
+
+// WithExpiryDelta set duration before expiry when token refreshed, by default 10 seconds
+func WithExpiryDelta(delta time.Duration) Option {
+	return func(c *config) {
+		c.expiryDelta = delta
+	}
+}
+
+// WithCredentialsInBody send client credentials by the body params instead of the basic auth
+func WithCredentialsInBody() Option {
+	return func(c *config) {
+		c.basicAuth = false
+	}
+}
+
+// NewClientCredentials create source of the client credentials grant
+func NewClientCredentials(tokenURL, clientID, clientSecret string, opts ...Option) (*Source, error) {
+	return newSource(tokenURL, clientID, clientSecret, "", opts...)
+}
+
+// NewRefreshToken create source of the refresh token grant
+// the refresh token replaced when the token endpoint issue new one
+func NewRefreshToken(tokenURL, clientID, clientSecret, refreshToken string, opts ...Option) (*Source, error) {
+	return newSource(tokenURL, clientID, clientSecret, refreshToken, opts...)
+}
+
+func newSource(tokenURL, clientID, clientSecret, refreshToken string, opts ...Option) (*Source, error) {
+	cfg := &config{
+		httpClient:  http.DefaultClient,
+		params:      url.Values{},
+		expiryDelta: 10 * time.Second,
+		basicAuth:   true,
+		now:         time.Now,
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	cl, err := dc.New(tokenURL,
+		dc.WithHTTPClient(cfg.httpClient),
+		dc.WithDecoder(func(r io.Reader, v interface{}) error {
+			return json.NewDecoder(r).Decode(v)
+		}),
+		dc.WithHTTPErrorMiddleware(http.StatusBadRequest, func(r *http.Response) error {
+			return &Error{StatusCode: r.StatusCode}
+		}, func(r *http.Response, body io.Reader, v interface{}) error {
+			// the error body is optional and may be not json
+			_ = json.NewDecoder(body).Decode(v)
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{
+		cfg:          cfg,
+		client:       cl,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+	}, nil
+}
+
+type call struct {
+	done     chan struct{}
+	token    *Token
+	err      error
+	canceled bool
+}
+
+// Source get tokens by the token endpoint and cache them until shortly before expiry
+// concurrent requests of the expired token share one request to the token endpoint
+type Source struct {
+	cfg          *config
+	client       *dc.Client
+	clientID     string
+	clientSecret string
+	assertion    func(now time.Time) (string, error)
+
+	mu           sync.Mutex
+	token        *Token
+	refreshToken string
+	call         *call
+}
+
+// Token get cached or fetch new token
+func (s *Source) Token(ctx context.Context) (*Token, error) {
+	for {
+		s.mu.Lock()
+		if s.token.Valid(s.cfg.now(), s.cfg.expiryDelta) {
+			t := s.token
+			s.mu.Unlock()
+			return t, nil
+		}
+		if c := s.call; c != nil {
+			s.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-c.done:
+			}
+			// the leader request canceled by own context, fetch token by the waiter context
+			if c.canceled {
+				continue
+			}
+			return c.token, c.err
+		}
+		c := &call{done: make(chan struct{})}
+		s.call = c
+		refreshToken := s.refreshToken
+		s.mu.Unlock()
+
+		c.token, c.err = s.fetch(ctx, refreshToken)
+		c.canceled = c.err != nil && ctx.Err() != nil
+
+		s.mu.Lock()
+		s.call = nil
+		if c.err == nil {
+			s.token = c.token
+			if c.token.RefreshToken != "" && s.refreshToken != "" {
+				s.refreshToken = c.token.RefreshToken
+			}
+		}
+		s.mu.Unlock()
+		close(c.done)
+
+		return c.token, c.err
+	}
+}
+
+// Invalidate cached token when it rejected by the server
+func (s *Source) Invalidate(t *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == t {
+		s.token = nil
+	}
+}
+
+func (s *Source) fetch(ctx context.Context, refreshToken string) (*Token, error) {
+	form := url.Values{}
+	for name, values := range s.cfg.params {
+		form[name] = values
+	}
+	if refreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", refreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	if len(s.cfg.scopes) > 0 {
+		form.Set("scope", strings.Join(s.cfg.scopes, " "))
+	}
+	switch {
+	case s.assertion != nil:
+		assertion, err := s.assertion(s.cfg.now())
+		if err != nil {
+			return nil, err
+		}
+		form.Set("client_id", s.clientID)
+		form.Set("client_assertion_type", AssertionType)
+		form.Set("client_assertion", assertion)
+	case !s.cfg.basicAuth:
+		form.Set("client_id", s.clientID)
+		form.Set("client_secret", s.clientSecret)
+	}
+
+	r, err := http.NewRequest(http.MethodPost, "", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", "application/json")
+	if s.cfg.basicAuth && s.assertion == nil {
+		r.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+	}
+
+	start := s.cfg.now()
+	t := &Token{}
+	if err := s.client.Do(r.WithContext(ctx), t); err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, &Error{Code: "invalid_response", Description: "access token is empty", StatusCode: http.StatusOK}
+	}
+	if t.ExpiresIn > 0 {
+		t.Expiry = start.Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,62 @@
+package secret
+
+import (
+	"net/http"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+type config struct {
+	inject func(r *http.Request, credential string)
+}
+
+// Option configure middleware
+type Option func(c *config)
+
+// WithBearer set credential to the Authorization header as bearer token, by default
+func WithBearer() Option {
+	return WithHeader("Authorization", "Bearer ")
+}
+
+// WithHeader set credential with prefix to the header like X-Api-Key
+func WithHeader(name, prefix string) Option {
+	return func(c *config) {
+		c.inject = func(r *http.Request, credential string) {
+			r.Header = transport.CloneHeader(r.Header)
+			r.Header.Set(name, prefix+credential)
+		}
+	}
+}
+
+// WithQuery set credential to the query parameter like api_key
+func WithQuery(name string) Option {
+	return func(c *config) {
+		c.inject = func(r *http.Request, credential string) {
+			u := *r.URL
+			q := u.Query()
+			q.Set(name, credential)
+			u.RawQuery = q.Encode()
+			r.URL = &u
+		}
+	}
+}
+
+// New create middleware which authorize requests by the current credential of the provider
+func New(p CredentialProvider, opts ...Option) transport.Middleware {
+	cfg := config{}
+	WithBearer()(&cfg)
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		credential, err := p.Credential(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		ar := r.WithContext(r.Context())
+		cfg.inject(ar, credential)
+
+		return n(ar)
+	}
+}
@@ -0,0 +1,127 @@
+package secret
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/dc"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	cl := dc.Must("https://api.example.com", dc.WithMiddleware(
+		New(NewFile("/var/run/secrets/api/token"), WithHeader("X-Api-Key", "")),
+	))
+	req, _ := http.NewRequest(http.MethodGet, "/user/1", nil)
+	var user struct {
+		ID   int
+		Name string
+	}
+	if err := cl.Do(req, &user); err != nil {
+		log.Fatal(err)
+	}
+	log.Print(user)
+}
+
+func TestNew(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/api?page=1", nil)
+	require.Nil(t, err)
+
+	cases := map[string]struct {
+		opts   []Option
+		header string
+		value  string
+		url    string
+	}{
+		"bearer": {header: "Authorization", value: "Bearer token", url: "https://example.com/api?page=1"},
+		"header": {opts: []Option{WithHeader("X-Api-Key", "")}, header: "X-Api-Key", value: "token", url: "https://example.com/api?page=1"},
+		"query":  {opts: []Option{WithQuery("api_key")}, url: "https://example.com/api?api_key=token&page=1"},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			_, err := New(Static("token"), c.opts...)(req, func(r *http.Request) (*http.Response, error) {
+				if c.header != "" {
+					require.Equal(t, c.value, r.Header.Get(c.header))
+				}
+				require.Equal(t, c.url, r.URL.String())
+
+				return nil, nil
+			})
+			require.Nil(t, err)
+			require.Empty(t, req.Header)
+			require.Equal(t, "page=1", req.URL.RawQuery)
+		})
+	}
+
+	_, err = New(Static(""))(req, nil)
+	require.Equal(t, ErrNotFound, err)
+}
+
+func TestEnv(t *testing.T) {
+	const name = "HTTPCLIENT_SECRET_TEST"
+	_, err := Env(name).Credential(context.Background())
+	require.Equal(t, ErrNotFound, err)
+
+	require.Nil(t, os.Setenv(name, "first"))
+	defer os.Unsetenv(name)
+	value, err := Env(name).Credential(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "first", value)
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secret")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token")
+
+	now := time.Unix(1600000000, 0)
+	f := NewFile(path, WithCheckInterval(time.Minute))
+	f.now = func() time.Time {
+		return now
+	}
+	ctx := context.Background()
+
+	_, err = f.Credential(ctx)
+	require.True(t, os.IsNotExist(err))
+
+	require.Nil(t, ioutil.WriteFile(path, []byte("first\n"), 0600))
+	value, err := f.Credential(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "first", value)
+
+	require.Nil(t, ioutil.WriteFile(path, []byte("second-token\n"), 0600))
+	value, err = f.Credential(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "first", value, "cached until the check interval")
+
+	now = now.Add(time.Minute)
+	value, err = f.Credential(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "second-token", value)
+
+	require.Nil(t, os.Remove(path))
+	now = now.Add(time.Minute)
+	value, err = f.Credential(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "second-token", value, "the last value while the file is swapped")
+
+	require.Nil(t, ioutil.WriteFile(path, []byte("third\n"), 0600))
+	value, err = f.Credential(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "third", value, "the failed reload retried without the check interval")
+
+	require.Nil(t, ioutil.WriteFile(path, []byte(" \n"), 0600))
+	now = now.Add(time.Minute)
+	_, err = f.Credential(ctx)
+	require.Equal(t, ErrNotFound, err)
+	_, err = f.Credential(ctx)
+	require.Equal(t, ErrNotFound, err, "the cleared secret is not cached")
+}
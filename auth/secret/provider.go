@@ -0,0 +1,142 @@
+// Package secret authorize requests by the rotated api keys and bearer tokens
+package secret
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNotFound the credential is not set
+var ErrNotFound = errors.New("secret: credential not found")
+
+// CredentialProvider get current credential
+type CredentialProvider interface {
+	Credential(ctx context.Context) (string, error)
+}
+
+var (
+	_ CredentialProvider = ProviderFunc(nil)
+	_ CredentialProvider = Static("")
+	_ CredentialProvider = Env("")
+	_ CredentialProvider = &File{}
+)
+
+// ProviderFunc get credential by the func
+type ProviderFunc func(ctx context.Context) (string, error)
+
+// Credential get credential by the func
+func (f ProviderFunc) Credential(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// Static credential
+type Static string
+
+// Credential get static value
+func (s Static) Credential(context.Context) (string, error) {
+	if s == "" {
+		return "", ErrNotFound
+	}
+
+	return string(s), nil
+}
+
+// Env credential by the name of the environment variable
+type Env string
+
+// Credential get value of the environment variable
+func (e Env) Credential(context.Context) (string, error) {
+	value, ok := os.LookupEnv(string(e))
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+
+	return value, nil
+}
+
+// FileOption configure file provider
+type FileOption func(f *File)
+
+// WithCheckInterval set min interval of the file change check, by default 10 seconds
+func WithCheckInterval(d time.Duration) FileOption {
+	return func(f *File) {
+		f.interval = d
+	}
+}
+
+// NewFile create provider of the credential stored in the file like mounted secret
+// the file reloaded when modification time or size changed
+func NewFile(path string, opts ...FileOption) *File {
+	f := &File{
+		path:     path,
+		interval: 10 * time.Second,
+		now:      time.Now,
+	}
+	for _, o := range opts {
+		o(f)
+	}
+
+	return f
+}
+
+// File provider of the credential
+type File struct {
+	path     string
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	value   string
+	modTime time.Time
+	size    int64
+	checked time.Time
+}
+
+// Credential get content of the file without surrounding spaces
+// the last read value returned while the file can't be read, like on the swap of the mounted secret
+func (f *File) Credential(context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	if f.value != "" && now.Sub(f.checked) < f.interval {
+		return f.value, nil
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return f.cached(err)
+	}
+	if f.value != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		f.checked = now
+		return f.value, nil
+	}
+
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return f.cached(err)
+	}
+	f.checked = now
+	value := string(bytes.TrimSpace(b))
+	if value == "" {
+		f.value = ""
+		return "", ErrNotFound
+	}
+	f.value, f.modTime, f.size = value, info.ModTime(), info.Size()
+
+	return f.value, nil
+}
+
+// cached get the last read value on the error of the reload, the reload retried by the next call
+func (f *File) cached(err error) (string, error) {
+	if f.value == "" {
+		return "", err
+	}
+
+	return f.value, nil
+}
@@ -0,0 +1,82 @@
+package dc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"sync"
+)
+
+const (
+	// maxPresize max size of the buffer pre-sized by content length
+	maxPresize = 1 << 20
+	// maxPooled max capacity of the buffer returned to the pool
+	maxPooled = 1 << 16
+)
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooled {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+type maxBodySizeKey struct{}
+
+// ContextMaxBodySize override max size of the response body for the request context, zero is unlimited
+func ContextMaxBodySize(ctx context.Context, size int64) context.Context {
+	return context.WithValue(ctx, maxBodySizeKey{}, size)
+}
+
+// ErrBodyTooLarge response body exceeded max size
+type ErrBodyTooLarge struct {
+	MaxSize int64
+	// ContentLength declared by response, -1 when unknown
+	ContentLength int64
+}
+
+func (e *ErrBodyTooLarge) Error() string {
+	msg := "http client: response body too large, max size " + strconv.FormatInt(e.MaxSize, 10)
+	if e.ContentLength >= 0 {
+		msg += ", content length " + strconv.FormatInt(e.ContentLength, 10)
+	}
+
+	return msg
+}
+
+// limitReader read n bytes and return err when body has more
+type limitReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			return 0, l.err
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
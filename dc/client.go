@@ -15,6 +15,7 @@ import (
 )
 
 var _ httpclient.Fetcher = &Client{}
+var _ httpclient.StreamFetcher = &Client{}
 var _ httpclient.Client = &Client{}
 
 // ErrEmptyBody base errors
@@ -28,23 +29,19 @@ type Client struct {
 	decoder    decoder.Decoder
 	baseURL    url.URL
 	with       func(*http.Response, io.Reader) error
-	middleware transport.Middleware
+	middleware []transport.Middleware
+	handle     transport.Handler
+	maxBody    int64
 }
 
 // Option for the configure Client
 type Option func(*Client)
 
 // WithMiddleware add middleware do request
+// middlewares composed once on create client
 func WithMiddleware(mw ...transport.Middleware) Option {
 	return func(i *Client) {
-		if len(mw) == 0 {
-			return
-		}
-		if i.middleware != nil {
-			i.middleware = transport.Chain(append([]transport.Middleware{i.middleware}, mw...)...)
-		} else {
-			i.middleware = transport.Chain(mw...)
-		}
+		i.middleware = append(i.middleware, mw...)
 	}
 }
 
@@ -124,6 +121,13 @@ func WithHTTPErrorMiddleware(minStatusCode int,
 	}
 }
 
+// WithMaxBodySize limit size of the response body, zero is unlimited
+func WithMaxBodySize(size int64) Option {
+	return func(i *Client) {
+		i.maxBody = size
+	}
+}
+
 // WithDecoder set decoder body
 func WithDecoder(decoder decoder.Decoder) Option {
 	return func(i *Client) {
@@ -165,60 +169,138 @@ func New(baseURL string, opts ...Option) (*Client, error) {
 		WithHTTPErrorMiddleware(http.StatusBadRequest, apierrors.HTTPErrorMessage, errDecoder)(cl)
 	}
 
+	if cl.httpClient == nil {
+		cl.httpClient = http.DefaultClient
+	}
+	cl.handle = transport.Handle(cl.httpClient.Do, cl.middleware...)
+
 	return cl, nil
 }
 
 // Do request and decode response body
+// the response body read to the pooled buffer which released after decode
 func (c *Client) Do(r *http.Request, v interface{}) error {
-	f := c.Fetch(r)
+	b := getBuffer()
+	defer putBuffer(b)
+
+	var f httpclient.Fetch = c.buffer(c.fetch(r), b)
 	if c.with != nil {
 		f = f.With(c.with)
 	}
 	return f.Decode(v)
 }
 
-// Fetch do request
+// DoStream request and decode response body without buffering
+func (c *Client) DoStream(r *http.Request, v interface{}) error {
+	f := c.FetchStream(r)
+	defer f.Close()
+
+	var res httpclient.Fetch = f
+	if c.with != nil {
+		res = res.With(c.with)
+	}
+	return res.Decode(v)
+}
+
+// Fetch do request and read whole body
 func (c *Client) Fetch(r *http.Request) httpclient.Fetch {
+	return c.buffer(c.fetch(r), &bytes.Buffer{})
+}
+
+// buffer read the response body to the buffer pre-sized by content length
+func (c *Client) buffer(f fetch, b *bytes.Buffer) fetch {
+	if f.err != nil || f.response.Body == nil {
+		return f
+	}
+	if size := f.response.ContentLength; size > 0 && size <= maxPresize {
+		b.Grow(int(size) + bytes.MinRead)
+	}
+	if _, err := io.Copy(b, f.body); err != nil {
+		f.err = err
+	}
+	f.body = b
+	_ = f.response.Body.Close()
+
+	return f
+}
+
+// FetchStream do request without buffering the body
+// the response body read by With, Decode or Body and must be closed by Close
+func (c *Client) FetchStream(r *http.Request) httpclient.StreamFetch {
+	f := c.fetch(r)
+	if f.err == nil && f.response.Body != nil {
+		f.closer = f.response.Body
+	}
+
+	return f
+}
+
+func (c *Client) fetch(r *http.Request) fetch {
 	f := fetch{
 		decode: c.decode,
 	}
-	if c.httpClient == nil {
-		c.httpClient = http.DefaultClient
-	}
 	r.URL, f.err = c.baseURL.Parse(r.URL.String())
 	if f.err != nil {
 		return f
 	}
-	res, err := func(req *http.Request) (*http.Response, error) {
-		if c.middleware != nil {
-			return c.middleware(r, c.httpClient.Do)
+	handle := c.handle
+	if handle == nil {
+		httpClient := c.httpClient
+		if httpClient == nil {
+			httpClient = http.DefaultClient
 		}
-		return c.httpClient.Do(req)
-	}(r)
-	if err != nil {
-		f.err = err
+		handle = transport.Handle(httpClient.Do, c.middleware...)
+	}
+	f.response, f.err = handle(r)
+	if f.response != nil && f.response.Request != nil {
+		f.timing = httpclient.TimingFrom(f.response.Request.Context())
+	}
+	if f.err != nil || f.response.Body == nil {
 		return f
 	}
-	f.response = res
-	if res.Body != nil {
-		var b bytes.Buffer
-		if _, err := io.Copy(&b, res.Body); err != nil {
-			f.err = err
+	f.body = f.response.Body
+	if size := c.maxBodySize(r); size > 0 {
+		if f.response.ContentLength > size {
+			_ = f.response.Body.Close()
+			f.err = &ErrBodyTooLarge{MaxSize: size, ContentLength: f.response.ContentLength}
+			return f
+		}
+		f.body = &limitReader{
+			r:   f.response.Body,
+			n:   size,
+			err: &ErrBodyTooLarge{MaxSize: size, ContentLength: f.response.ContentLength},
 		}
-		f.body = &b
-		_ = res.Body.Close()
 	}
 
 	return f
 }
 
+func (c *Client) maxBodySize(r *http.Request) int64 {
+	if size, ok := r.Context().Value(maxBodySizeKey{}).(int64); ok {
+		return size
+	}
+
+	return c.maxBody
+}
+
 type fetch struct {
 	body     io.Reader
+	closer   io.Closer
 	response *http.Response
 	err      error
+	timing   *httpclient.TimingRecorder
 	decode   func(r *http.Response, body io.Reader, v interface{}) error
 }
 
+// Close close the body of the stream response
+func (f fetch) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+
+	return f.closer.Close()
+}
+
 // Error get error by do
 func (f fetch) Error() error {
 	return f.err
@@ -254,6 +336,11 @@ func (f fetch) Body() io.Reader {
 	return f.body
 }
 
+// Timing get timing of the request recorded by the timing middleware
+func (f fetch) Timing() httpclient.Timing {
+	return f.timing.Timing()
+}
+
 func (c *Client) decode(r *http.Response, body io.Reader, v interface{}) error {
 	if body == nil {
 		return ErrEmptyBody
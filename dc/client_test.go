@@ -9,9 +9,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/go-4devs/httpclient"
 	"github.com/go-4devs/httpclient/decoder"
+	"github.com/go-4devs/httpclient/transport/timing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -200,6 +203,25 @@ func TestFetch_IsStatus(t *testing.T) {
 	t.Run("status not implemented", func(t *testing.T) {
 		require.True(t, cl.Fetch(getRequest(t, "")).IsStatus(http.StatusNotImplemented))
 	})
+	t.Run("nil http client", func(t *testing.T) {
+		require.True(t, Must(s.URL, WithHTTPClient(nil)).Fetch(getRequest(t, uriOK)).IsStatus(http.StatusOK))
+	})
+}
+
+func TestFetch_Timing(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	f := Must(s.URL).Fetch(getRequest(t, uriOK))
+	require.Nil(t, f.Error())
+	require.Equal(t, httpclient.Timing{}, f.Timing())
+
+	f = Must(s.URL, WithMiddleware(timing.New())).Fetch(getRequest(t, uriOK))
+	require.Nil(t, f.Error())
+	tm := f.Timing()
+	require.True(t, tm.Connect > 0 || tm.Reused)
+	require.True(t, tm.FirstByte > 0)
+	require.True(t, tm.Total >= tm.FirstByte)
 }
 
 func getRequest(t *testing.T, url string) *http.Request {
@@ -256,3 +278,167 @@ func TestClient_Do(t *testing.T) {
 		require.EqualError(t, c.Do(r, &jsonOk), "invalid character 'i' looking for beginning of value")
 	})
 }
+
+type testBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *testBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestClient_FetchStream(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+	cl := Must(s.URL)
+
+	f := cl.FetchStream(getRequest(t, uriOK))
+	require.Nil(t, f.Error())
+	require.True(t, f.IsStatus(http.StatusOK))
+	_, ok := f.Body().(*bytes.Buffer)
+	require.False(t, ok)
+	bb, err := ioutil.ReadAll(f.Body())
+	require.Nil(t, err)
+	require.Equal(t, []byte(`{"ok":true}`), bb)
+	require.Nil(t, f.Close())
+
+	body := &testBody{Reader: bytes.NewBufferString(`{"ok":true}`)}
+	cl = Must(s.URL, WithMiddleware(func(r *http.Request,
+		next func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		res, err := next(r)
+		require.Nil(t, err)
+		require.Nil(t, res.Body.Close())
+		res.Body = body
+		return res, nil
+	}), WithDecoder(func(r io.Reader, v interface{}) error {
+		return json.NewDecoder(r).Decode(v)
+	}))
+
+	var res struct {
+		Ok bool
+	}
+	require.Nil(t, cl.DoStream(getRequest(t, uriOK), &res))
+	require.True(t, res.Ok)
+	require.True(t, body.closed)
+
+	f = Must(s.URL, WithMiddleware(func(r *http.Request,
+		next func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		return nil, ErrEmptyBody
+	})).FetchStream(getRequest(t, uriOK))
+	require.Error(t, f.Error())
+	require.Nil(t, f.Close())
+}
+
+func TestWithMaxBodySize(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+	cl := Must(s.URL, WithMaxBodySize(5))
+
+	f := cl.Fetch(getRequest(t, uriOK))
+	require.Equal(t, &ErrBodyTooLarge{MaxSize: 5, ContentLength: 11}, f.Error())
+	require.EqualError(t, f.Error(), "http client: response body too large, max size 5, content length 11")
+
+	r := getRequest(t, uriOK)
+	f = cl.Fetch(r.WithContext(ContextMaxBodySize(r.Context(), 11)))
+	require.Nil(t, f.Error())
+	bb, err := ioutil.ReadAll(f.Body())
+	require.Nil(t, err)
+	require.Equal(t, []byte(`{"ok":true}`), bb)
+
+	f = cl.Fetch(r.WithContext(ContextMaxBodySize(r.Context(), 0)))
+	require.Nil(t, f.Error())
+
+	cl = Must(s.URL, WithMaxBodySize(10), WithMiddleware(func(r *http.Request,
+		next func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		res, err := next(r)
+		if err == nil {
+			res.ContentLength = -1
+		}
+		return res, err
+	}))
+	f = cl.Fetch(getRequest(t, uriOK))
+	require.Equal(t, &ErrBodyTooLarge{MaxSize: 10, ContentLength: -1}, f.Error())
+	require.EqualError(t, f.Error(), "http client: response body too large, max size 10")
+
+	sf := cl.FetchStream(getRequest(t, uriOK))
+	require.Nil(t, sf.Error())
+	bb, err = ioutil.ReadAll(sf.Body())
+	require.Equal(t, &ErrBodyTooLarge{MaxSize: 10, ContentLength: -1}, err)
+	require.Equal(t, []byte(`{"ok":true`), bb)
+	require.Nil(t, sf.Close())
+
+	r = getRequest(t, uriOK)
+	f = cl.Fetch(r.WithContext(ContextMaxBodySize(r.Context(), 11)))
+	require.Nil(t, f.Error())
+}
+
+type benchTransport []byte
+
+func (b benchTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(len(b)),
+		Body:          ioutil.NopCloser(bytes.NewReader(b)),
+		Request:       r,
+	}, nil
+}
+
+func benchClient() *Client {
+	data := []byte(`{"id":1,"name":"name","items":["one","two","three"],"ok":true,"description":"` +
+		strings.Repeat("d", 2048) + `"}`)
+
+	return Must("http://example.com",
+		WithTransport(benchTransport(data)),
+		WithDecoder(func(r io.Reader, v interface{}) error {
+			return json.NewDecoder(r).Decode(v)
+		}),
+	)
+}
+
+type benchResponse struct {
+	ID    int
+	Name  string
+	Items []string
+	Ok    bool
+}
+
+func BenchmarkClient_Do(b *testing.B) {
+	cl := benchClient()
+	r, _ := http.NewRequest(http.MethodGet, "/api", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var res benchResponse
+		if err := cl.Do(r, &res); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkClient_Fetch(b *testing.B) {
+	cl := benchClient()
+	r, _ := http.NewRequest(http.MethodGet, "/api", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var res benchResponse
+		if err := cl.Fetch(r).With(cl.with).Decode(&res); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestClient_DoPool(t *testing.T) {
+	cl := benchClient()
+	r, err := http.NewRequest(http.MethodGet, "/api", nil)
+	require.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		var res benchResponse
+		require.Nil(t, cl.Do(r, &res))
+		require.Equal(t, benchResponse{ID: 1, Name: "name", Items: []string{"one", "two", "three"}, Ok: true}, res)
+	}
+}
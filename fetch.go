@@ -12,6 +12,8 @@ type Fetch interface {
 	Decode(v interface{}) error
 	Body() io.Reader
 	Error() error
+	// Timing of the request recorded by the timing middleware, zero when not recorded
+	Timing() Timing
 }
 
 // Fetcher fetch response
@@ -19,3 +21,15 @@ type Fetcher interface {
 	Client
 	Fetch(r *http.Request) Fetch
 }
+
+// StreamFetch fetch with the live response body which must be closed
+type StreamFetch interface {
+	Fetch
+	io.Closer
+}
+
+// StreamFetcher fetch response without buffering body
+type StreamFetcher interface {
+	Fetcher
+	FetchStream(r *http.Request) StreamFetch
+}
@@ -8,12 +8,19 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
 )
 
 // Encoder for the body
 type Encoder func(v interface{}) (io.Reader, error)
 
+// ErrContext next of the middleware called by the context not derived from the context of the middleware
+var ErrContext = errors.New("request: next called without client request in the context")
+
 // Middleware handle middleware
+// n must be called by the ctx or the context derived from it, the context bound to the client request
 type Middleware func(ctx context.Context, cr *ClientRequest,
 	n func(context.Context) (*http.Request, error)) (*http.Request, error)
 
@@ -27,7 +34,8 @@ type ClientRequest struct {
 	query    url.Values
 	err      error
 	ctx      context.Context
-	mw       Middleware
+	mw       []Middleware
+	next     handler
 }
 
 // Option configure client request
@@ -43,11 +51,8 @@ func WithEncoder(encoder Encoder) Option {
 // WithMiddleware set middleware request
 func WithMiddleware(mw ...Middleware) Option {
 	return func(request *ClientRequest) {
-		if request.mw != nil {
-			request.mw = chain(append(mw, request.mw)...)
-		} else {
-			request.mw = chain(mw...)
-		}
+		request.mw = append(mw[:len(mw):len(mw)], request.mw...)
+		request.next = compose(initRequest, request.mw...)
 	}
 }
 
@@ -134,7 +139,8 @@ func (r ClientRequest) SetBasicAuth(username, password string) ClientRequest {
 	})
 }
 
-// SetBody encode body and add to request
+// SetBody encode body and add to request, the body always can be replayed by GetBody of the http request
+// the body as io.ReaderAt with io.Seeker like file is not closed by the transport, close it after the response
 func (r ClientRequest) SetBody(data interface{}) ClientRequest {
 	if r.err != nil {
 		return r
@@ -172,8 +178,8 @@ func (r ClientRequest) HTTP() (httpRequest *http.Request, err error) {
 	if r.ctx == nil {
 		r.ctx = context.Background()
 	}
-	if r.mw != nil {
-		httpRequest, err = r.mw(r.ctx, &r, r.init)
+	if r.next != nil {
+		httpRequest, err = r.next(&callContext{Context: r.ctx, cr: &r})
 	} else {
 		httpRequest, err = r.init(r.ctx)
 	}
@@ -187,11 +193,18 @@ func (r ClientRequest) HTTP() (httpRequest *http.Request, err error) {
 
 func (r ClientRequest) init(ctx context.Context) (request *http.Request, e error) {
 	request, e = http.NewRequest(r.Method, r.path(), r.Body)
-	if e == nil {
-		request = request.WithContext(ctx)
+	if e != nil {
+		return nil, e
 	}
+	if request.GetBody == nil && request.Body != nil {
+		if e = transport.SetBody(request, r.Body); e != nil {
+			return nil, e
+		}
+	}
+
+	ctx = httpclient.WithRoute(ctx, r.Path)
 
-	return request, e
+	return request.WithContext(ctx), nil
 }
 
 func (r ClientRequest) path() string {
@@ -208,45 +221,60 @@ func (r ClientRequest) path() string {
 }
 
 func (r ClientRequest) handle(h Middleware) ClientRequest {
-	if r.mw == nil {
-		r.mw = h
-	} else {
-		r.mw = chain(r.mw, h)
-	}
+	r.mw = append(r.mw[:len(r.mw):len(r.mw)], h)
+	r.next = compose(initRequest, r.mw...)
+
 	return r
 }
 
-// chain middleware
-func chain(handleFunc ...Middleware) Middleware {
-	n := len(handleFunc)
-	if n > 1 {
-		lastI := n - 1
-		return func(ctx context.Context, cr *ClientRequest,
-			n func(context.Context) (*http.Request, error)) (*http.Request, error) {
-			var (
-				chainHandler func(context.Context) (*http.Request, error)
-				curI         int
-			)
-			chainHandler = func(currentCtx context.Context) (*http.Request, error) {
-				if curI == lastI {
-					return n(currentCtx)
-				}
-				curI++
-				res, err := handleFunc[curI](currentCtx, cr, chainHandler)
-				curI--
-				return res, err
+type clientRequestKey struct{}
 
-			}
-			return handleFunc[0](ctx, cr, chainHandler)
-		}
+// callContext bind the client request of the call to the context of the middlewares
+type callContext struct {
+	context.Context
+	cr *ClientRequest
+}
+
+func (c *callContext) Value(key interface{}) interface{} {
+	if key == (clientRequestKey{}) {
+		return c.cr
 	}
 
-	if n == 1 {
-		return handleFunc[0]
+	return c.Context.Value(key)
+}
+
+type handler func(ctx context.Context) (*http.Request, error)
+
+// compose middlewares with the handler once, the client request of the call passed to the middlewares by the context
+// the composed handler does not allocate by chain on each call, does not share state and next can be called concurrently
+func compose(h handler, mw ...Middleware) handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = bind(mw[i], h)
+	}
+
+	return h
+}
+
+func bind(mw Middleware, next handler) handler {
+	return func(ctx context.Context) (*http.Request, error) {
+		cr, ok := ctx.Value(clientRequestKey{}).(*ClientRequest)
+		if !ok {
+			return nil, ErrContext
+		}
+
+		return mw(ctx, cr, next)
 	}
+}
 
-	return func(ctx context.Context, cr *ClientRequest,
-		n func(context.Context) (*http.Request, error)) (*http.Request, error) {
-		return n(ctx)
+func initRequest(ctx context.Context) (*http.Request, error) {
+	cr, ok := ctx.Value(clientRequestKey{}).(*ClientRequest)
+	if !ok {
+		return nil, ErrContext
+	}
+	// the request gets the context of the call when middlewares do not derive it
+	if c, ok := ctx.(*callContext); ok {
+		ctx = c.Context
 	}
+
+	return cr.init(ctx)
 }
@@ -7,9 +7,12 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-4devs/httpclient"
 	"github.com/stretchr/testify/require"
 )
 
@@ -125,7 +128,7 @@ func TestNewRequest(t *testing.T) {
 	require.Nil(t, e)
 	ex.Header.Add("x-header", "data")
 
-	require.Equal(t, ex.WithContext(ctx), r)
+	require.Equal(t, ex.WithContext(httpclient.WithRoute(ctx, "/path/to/the/page")), r)
 }
 
 func BenchmarkNewRequest(b *testing.B) {
@@ -156,3 +159,119 @@ func requireTime(t *testing.T, value string) time.Time {
 
 	return ti
 }
+
+type testReader struct {
+	r io.Reader
+}
+
+func (t testReader) Read(p []byte) (int, error) {
+	return t.r.Read(p)
+}
+
+type testSeeker struct {
+	*strings.Reader
+}
+
+func TestClientRequest_GetBody(t *testing.T) {
+	r := NewPost(context.Background())
+	body := testSeeker{Reader: strings.NewReader("skip some data in body")}
+	_, e := body.Seek(5, io.SeekStart)
+	require.Nil(t, e)
+
+	for _, data := range []io.Reader{body, testReader{r: strings.NewReader("some data in body")}} {
+		h, e := r.SetBody(data).HTTP()
+		require.Nil(t, e)
+		require.Equal(t, int64(17), h.ContentLength)
+		require.NotNil(t, h.GetBody)
+
+		for i := 0; i < 2; i++ {
+			body, err := h.GetBody()
+			require.Nil(t, err)
+			b, err := ioutil.ReadAll(body)
+			require.Nil(t, err)
+			require.Equal(t, "some data in body", string(b))
+		}
+		b, e := ioutil.ReadAll(h.Body)
+		require.Nil(t, e)
+		require.Equal(t, "some data in body", string(b), "the copies of GetBody do not read the body")
+	}
+
+	h, e := r.SetBody(testSeeker{Reader: strings.NewReader("")}).HTTP()
+	require.Nil(t, e)
+	require.Equal(t, http.NoBody, h.Body)
+}
+
+func TestCompose_Concurrent(t *testing.T) {
+	concurrent := func(ctx context.Context, cr *ClientRequest,
+		n func(context.Context) (*http.Request, error)) (*http.Request, error) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = n(ctx)
+			}()
+		}
+		wg.Wait()
+		return n(ctx)
+	}
+
+	r, e := NewRequest(context.Background(), WithMiddleware(concurrent)).
+		Header(StringValue("x-header", "data")).
+		SetBasicAuth("username", "password").
+		HTTP()
+	require.Nil(t, e)
+	require.Equal(t, "data", r.Header.Get("x-header"))
+	require.Equal(t, "Basic dXNlcm5hbWU6cGFzc3dvcmQ=", r.Header.Get("Authorization"))
+}
+
+func TestClientRequest_Route(t *testing.T) {
+	r, e := NewGet(context.Background()).URI("/user/%d", 1).HTTP()
+	require.Nil(t, e)
+	require.Equal(t, "/user/1", r.URL.String())
+	require.Equal(t, "/user/%d", httpclient.Route(r.Context()))
+
+	r, e = NewGet(context.Background()).URI("/user").HTTP()
+	require.Nil(t, e)
+	require.Equal(t, "/user", httpclient.Route(r.Context()))
+}
+
+var (
+	benchRequest    = &http.Request{}
+	benchMiddleware = func(ctx context.Context, cr *ClientRequest,
+		n func(context.Context) (*http.Request, error)) (*http.Request, error) {
+		return n(ctx)
+	}
+)
+
+func benchInit(context.Context) (*http.Request, error) {
+	return benchRequest, nil
+}
+
+func TestCompose_Allocs(t *testing.T) {
+	ctx := &callContext{Context: context.Background(), cr: &ClientRequest{}}
+	h := compose(benchInit, benchMiddleware, benchMiddleware, benchMiddleware)
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = h(ctx)
+	})
+	require.Equal(t, float64(0), allocs)
+}
+
+func TestCompose_Context(t *testing.T) {
+	_, e := NewGet(context.Background(), WithMiddleware(func(ctx context.Context, cr *ClientRequest,
+		n func(context.Context) (*http.Request, error)) (*http.Request, error) {
+		return n(context.Background())
+	})).HTTP()
+	require.Equal(t, ErrContext, e)
+}
+
+func BenchmarkCompose(b *testing.B) {
+	ctx := &callContext{Context: context.Background(), cr: &ClientRequest{}}
+	h := compose(benchInit, benchMiddleware, benchMiddleware, benchMiddleware)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = h(ctx)
+	}
+}
@@ -2,4 +2,13 @@ module github.com/go-4devs/httpclient/request
 
 go 1.12
 
-require github.com/stretchr/testify v1.3.0
+replace (
+	github.com/go-4devs/httpclient => ../
+	github.com/go-4devs/httpclient/transport => ../transport
+)
+
+require (
+	github.com/go-4devs/httpclient v0.0.2
+	github.com/go-4devs/httpclient/transport v0.0.2
+	github.com/stretchr/testify v1.3.0
+)
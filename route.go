@@ -0,0 +1,21 @@
+package httpclient
+
+import (
+	"context"
+)
+
+type routeKey struct{}
+
+// WithRoute set route template of the request to the context
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// Route get route template of the request by context
+func Route(ctx context.Context) string {
+	if route, ok := ctx.Value(routeKey{}).(string); ok {
+		return route
+	}
+
+	return ""
+}
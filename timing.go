@@ -0,0 +1,66 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Timing breakdown of the request
+type Timing struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// FirstByte duration from the start of the request to the first byte of the response
+	FirstByte time.Duration
+	// Body duration from the response headers to the end of the response body
+	Body time.Duration
+	// Total duration from the start of the request to the end of the response body
+	Total time.Duration
+	// Reused connection from the pool
+	Reused bool
+}
+
+// Network duration of the connection establishment
+func (t Timing) Network() time.Duration {
+	return t.DNS + t.Connect + t.TLS
+}
+
+// TimingRecorder keeps timing of the request which can be updated concurrently
+type TimingRecorder struct {
+	mu     sync.Mutex
+	timing Timing
+}
+
+// Timing get snapshot of the timing
+func (t *TimingRecorder) Timing() Timing {
+	if t == nil {
+		return Timing{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.timing
+}
+
+// Update timing
+func (t *TimingRecorder) Update(fn func(t *Timing)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fn(&t.timing)
+}
+
+type timingKey struct{}
+
+// WithTiming set recorder of the timing to the context
+func WithTiming(ctx context.Context, t *TimingRecorder) context.Context {
+	return context.WithValue(ctx, timingKey{}, t)
+}
+
+// TimingFrom get recorder of the timing by context, nil when timing not recorded
+func TimingFrom(ctx context.Context) *TimingRecorder {
+	t, _ := ctx.Value(timingKey{}).(*TimingRecorder)
+
+	return t
+}
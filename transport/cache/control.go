@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// control directives of the Cache-Control header
+type control map[string]string
+
+func parseControl(h http.Header) control {
+	cc := control{}
+	for _, val := range h[http.CanonicalHeaderKey("Cache-Control")] {
+		for _, part := range strings.Split(val, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, value := part, ""
+			if i := strings.IndexByte(part, '='); i >= 0 {
+				name, value = part[:i], strings.Trim(strings.TrimSpace(part[i+1:]), `"`)
+			}
+			cc[strings.ToLower(strings.TrimSpace(name))] = value
+		}
+	}
+
+	return cc
+}
+
+func (c control) has(name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+// seconds get duration of the directive
+func (c control) seconds(name string) (time.Duration, bool) {
+	val, ok := c[name]
+	if !ok {
+		return 0, false
+	}
+	sec, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || sec < 0 {
+		return 0, false
+	}
+
+	return time.Duration(sec) * time.Second, true
+}
+
+func headerTime(h http.Header, name string) (time.Time, bool) {
+	val := h.Get(name)
+	if val == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(val)
+
+	return t, err == nil
+}
+
+// heuristic status codes cacheable by default
+var heuristic = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// lifetime freshness lifetime of the response
+func (e *entry) lifetime() time.Duration {
+	cc := parseControl(e.Header)
+	if d, ok := cc.seconds("max-age"); ok {
+		return d
+	}
+
+	date, ok := headerTime(e.Header, "Date")
+	if !ok {
+		date = e.ResponseTime
+	}
+	if expires := e.Header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil || !t.After(date) {
+			return 0
+		}
+		return t.Sub(date)
+	}
+
+	if lm, ok := headerTime(e.Header, "Last-Modified"); ok && heuristic[e.StatusCode] && date.After(lm) {
+		return date.Sub(lm) / 10
+	}
+
+	return 0
+}
+
+// age current age of the response
+func (e *entry) age(now time.Time) time.Duration {
+	var apparent, corrected time.Duration
+	if date, ok := headerTime(e.Header, "Date"); ok && e.ResponseTime.After(date) {
+		apparent = e.ResponseTime.Sub(date)
+	}
+	if sec, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil && sec > 0 {
+		corrected = time.Duration(sec) * time.Second
+	}
+	corrected += e.ResponseTime.Sub(e.RequestTime)
+	if apparent > corrected {
+		corrected = apparent
+	}
+
+	return corrected + now.Sub(e.ResponseTime)
+}
+
+// staleness how long response is stale, negative when response fresh
+func (e *entry) staleness(req control, now time.Time) time.Duration {
+	lifetime := e.lifetime()
+	if d, ok := req.seconds("max-age"); ok && d < lifetime {
+		lifetime = d
+	}
+	age := e.age(now)
+	if d, ok := req.seconds("min-fresh"); ok {
+		age += d
+	}
+
+	return age - lifetime
+}
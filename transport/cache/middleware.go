@@ -0,0 +1,323 @@
+// Package cache cache responses by the http caching RFC 9111
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+// XFromCache header set to the response served from the cache
+const XFromCache = "X-From-Cache"
+
+type entry struct {
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+	Vary         http.Header
+	RequestTime  time.Time
+	ResponseTime time.Time
+}
+
+func (e *entry) response(r *http.Request, now time.Time) *http.Response {
+	h := transport.CloneHeader(e.Header)
+	h.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	h.Set(XFromCache, "1")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       r,
+	}
+}
+
+// match check request headers selected by Vary
+func (e *entry) match(r *http.Request) bool {
+	for name, values := range e.Vary {
+		if strings.Join(r.Header[name], ",") != strings.Join(values, ",") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// update headers by not modified response
+// nolint: bodyclose
+func (e *entry) update(res *http.Response) {
+	for name, values := range res.Header {
+		switch name {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding":
+			continue
+		}
+		e.Header[name] = values
+	}
+}
+
+type config struct {
+	store   Store
+	key     func(r *http.Request) string
+	now     func() time.Time
+	timeout time.Duration
+	// revalidating keys of the background revalidations
+	revalidating sync.Map
+}
+
+// Option configure cache
+type Option func(c *config)
+
+// WithKey set key of the cached response, by default url
+func WithKey(fn func(r *http.Request) string) Option {
+	return func(c *config) {
+		c.key = fn
+	}
+}
+
+// WithRevalidateTimeout set timeout of the background stale-while-revalidate request, by default 30 seconds
+func WithRevalidateTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// New create cache middleware which keeps GET responses in the store
+func New(store Store, opts ...Option) transport.Middleware {
+	cfg := &config{
+		store: store,
+		key: func(r *http.Request) string {
+			return r.URL.String()
+		},
+		now:     time.Now,
+		timeout: 30 * time.Second,
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg.handle
+}
+
+func (c *config) handle(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+	key := c.key(r)
+	if r.Method != http.MethodGet {
+		res, err := n(r)
+		if err == nil && !safe(r.Method) && res.StatusCode < http.StatusBadRequest {
+			c.store.Delete(key)
+		}
+		return res, err
+	}
+
+	reqCC := parseControl(r.Header)
+	if reqCC.has("no-store") || r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+		return n(r)
+	}
+
+	e := c.load(key, r)
+	now := c.now()
+	if e == nil {
+		if reqCC.has("only-if-cached") {
+			return &http.Response{
+				Status:     fmt.Sprintf("%d %s", http.StatusGatewayTimeout, http.StatusText(http.StatusGatewayTimeout)),
+				StatusCode: http.StatusGatewayTimeout,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+				Request:    r,
+			}, nil
+		}
+		return c.fetch(r, n, key)
+	}
+
+	resCC := parseControl(e.Header)
+	stale := e.staleness(reqCC, now)
+	revalidate := reqCC.has("no-cache") || resCC.has("no-cache")
+	if reqCC.has("only-if-cached") || (!revalidate && stale < 0) {
+		return e.response(r, now), nil
+	}
+
+	if !revalidate && !resCC.has("must-revalidate") {
+		if d, ok := reqCC.seconds("max-stale"); (ok && stale <= d) || (reqCC.has("max-stale") && reqCC["max-stale"] == "") {
+			return e.response(r, now), nil
+		}
+		if d, ok := resCC.seconds("stale-while-revalidate"); ok && stale <= d {
+			c.background(r, n, key, e)
+			return e.response(r, now), nil
+		}
+	}
+
+	return c.revalidate(r, n, key, e)
+}
+
+// background revalidate stale response once per key at a time with the timeout
+func (c *config) background(r *http.Request, n func(r *http.Request) (*http.Response, error), key string, e *entry) {
+	if _, loaded := c.revalidating.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	go func() {
+		defer c.revalidating.Delete(key)
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+		res, err := c.revalidate(r.WithContext(ctx), n, key, e)
+		if err == nil {
+			_, _ = io.Copy(ioutil.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+	}()
+}
+
+// safe methods do not change resources
+func safe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	return false
+}
+
+func (c *config) load(key string, r *http.Request) *entry {
+	b, ok := c.store.Get(key)
+	if !ok {
+		return nil
+	}
+	var e entry
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&e); err != nil || !e.match(r) {
+		return nil
+	}
+
+	return &e
+}
+
+func (c *config) fetch(r *http.Request, n func(r *http.Request) (*http.Response, error), key string) (*http.Response, error) {
+	requestTime := c.now()
+	res, err := n(r)
+	if err != nil {
+		return res, err
+	}
+
+	return c.save(r, res, key, requestTime)
+}
+
+func (c *config) revalidate(r *http.Request, n func(r *http.Request) (*http.Response, error),
+	key string, e *entry) (*http.Response, error) {
+	req := r.WithContext(r.Context())
+	req.Header = transport.CloneHeader(r.Header)
+	if etag := e.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lm := e.Header.Get("Last-Modified"); lm != "" {
+		req.Header.Set("If-Modified-Since", lm)
+	}
+
+	requestTime := c.now()
+	res, err := n(req)
+	now := c.now()
+	if err != nil || res.StatusCode >= http.StatusInternalServerError {
+		if c.staleIfError(r, e, now) {
+			if res != nil {
+				_ = res.Body.Close()
+			}
+			return e.response(r, now), nil
+		}
+		return res, err
+	}
+
+	if res.StatusCode == http.StatusNotModified {
+		_ = res.Body.Close()
+		e.update(res)
+		e.RequestTime, e.ResponseTime = requestTime, now
+		c.set(key, e)
+		return e.response(r, now), nil
+	}
+	res.Request = r
+
+	return c.save(r, res, key, requestTime)
+}
+
+func (c *config) staleIfError(r *http.Request, e *entry, now time.Time) bool {
+	reqCC, resCC := parseControl(r.Header), parseControl(e.Header)
+	stale := e.staleness(reqCC, now)
+	if d, ok := reqCC.seconds("stale-if-error"); ok && stale <= d {
+		return true
+	}
+	d, ok := resCC.seconds("stale-if-error")
+
+	return ok && stale <= d
+}
+
+// nolint: bodyclose
+func (c *config) save(r *http.Request, res *http.Response, key string, requestTime time.Time) (*http.Response, error) {
+	if !cacheable(r, res) {
+		return res, nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	e := &entry{
+		StatusCode:   res.StatusCode,
+		Header:       transport.CloneHeader(res.Header),
+		Body:         body,
+		Vary:         http.Header{},
+		RequestTime:  requestTime,
+		ResponseTime: c.now(),
+	}
+	for _, val := range res.Header[http.CanonicalHeaderKey("Vary")] {
+		for _, name := range strings.Split(val, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name != "" {
+				e.Vary[name] = r.Header[name]
+			}
+		}
+	}
+	c.set(key, e)
+
+	return res, nil
+}
+
+func (c *config) set(key string, e *entry) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(e); err == nil {
+		c.store.Set(key, b.Bytes())
+	}
+}
+
+// cacheable check response can be stored
+// nolint: bodyclose
+func cacheable(r *http.Request, res *http.Response) bool {
+	if res.StatusCode == http.StatusPartialContent || r.Header.Get("Range") != "" {
+		return false
+	}
+	cc := parseControl(res.Header)
+	if cc.has("no-store") || strings.Contains(res.Header.Get("Vary"), "*") {
+		return false
+	}
+	explicit := cc.has("max-age") || res.Header.Get("Expires") != ""
+	if !heuristic[res.StatusCode] {
+		return explicit && (res.StatusCode == http.StatusFound || res.StatusCode == http.StatusTemporaryRedirect)
+	}
+
+	return explicit || res.Header.Get("ETag") != "" || res.Header.Get("Last-Modified") != ""
+}
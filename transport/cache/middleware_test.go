@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	mw := New(NewLRU(1000))
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, mw),
+	}
+	r, err := cl.Get("http://google.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r.Header.Get(XFromCache))
+}
+
+type testServer struct {
+	cnt    int32
+	now    time.Time
+	handle func(r *http.Request, res *http.Response)
+}
+
+func (s *testServer) next(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&s.cnt, 1)
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Request:    r,
+	}
+	res.Header.Set("Date", s.now.UTC().Format(http.TimeFormat))
+	s.handle(r, res)
+	if res.Body == nil {
+		res.Body = ioutil.NopCloser(bytes.NewBufferString("body"))
+	}
+
+	return res, nil
+}
+
+func (s *testServer) count() int32 {
+	return atomic.LoadInt32(&s.cnt)
+}
+
+func newHandler(s *testServer) transport.Handler {
+	cfg := &config{
+		store: NewLRU(10),
+		key: func(r *http.Request) string {
+			return r.URL.String()
+		},
+		now: func() time.Time {
+			return s.now
+		},
+		timeout: time.Second,
+	}
+
+	return transport.Handle(s.next, cfg.handle)
+}
+
+func get(t *testing.T, h transport.Handler, header ...string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, "http://google.com/data", nil)
+	require.Nil(t, err)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	res, err := h(req)
+	require.Nil(t, err)
+	b, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	require.Nil(t, res.Body.Close())
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	return res
+}
+
+// nolint: bodyclose
+func TestNew_MaxAge(t *testing.T) {
+	s := &testServer{now: time.Now(), handle: func(r *http.Request, res *http.Response) {
+		res.Header.Set("Cache-Control", "max-age=60")
+	}}
+	h := newHandler(s)
+
+	res := get(t, h)
+	require.Equal(t, "", res.Header.Get(XFromCache))
+	res = get(t, h)
+	require.Equal(t, "1", res.Header.Get(XFromCache))
+	require.Equal(t, "0", res.Header.Get("Age"))
+	require.Equal(t, int32(1), s.count())
+
+	s.now = s.now.Add(time.Second * 30)
+	res = get(t, h)
+	require.Equal(t, "30", res.Header.Get("Age"))
+	b, _ := ioutil.ReadAll(res.Body)
+	require.Equal(t, "body", string(b))
+
+	res = get(t, h, "Cache-Control", "max-age=10")
+	require.Equal(t, "", res.Header.Get(XFromCache))
+	require.Equal(t, int32(2), s.count())
+
+	s.now = s.now.Add(time.Second * 61)
+	res = get(t, h)
+	require.Equal(t, "", res.Header.Get(XFromCache))
+	require.Equal(t, int32(3), s.count())
+
+	res = get(t, h, "Cache-Control", "no-store")
+	require.Equal(t, "", res.Header.Get(XFromCache))
+	require.Equal(t, int32(4), s.count())
+}
+
+// nolint: bodyclose
+func TestNew_Expires(t *testing.T) {
+	s := &testServer{now: time.Now()}
+	s.handle = func(r *http.Request, res *http.Response) {
+		res.Header.Set("Expires", s.now.Add(time.Minute).UTC().Format(http.TimeFormat))
+	}
+	h := newHandler(s)
+	get(t, h)
+	require.Equal(t, "1", get(t, h).Header.Get(XFromCache))
+
+	s.now = s.now.Add(time.Minute * 2)
+	s.handle = func(r *http.Request, res *http.Response) {
+		res.Header.Set("Expires", "0")
+	}
+	require.Equal(t, "", get(t, h).Header.Get(XFromCache))
+	require.Equal(t, "", get(t, h).Header.Get(XFromCache))
+	require.Equal(t, int32(3), s.count())
+}
+
+// nolint: bodyclose
+func TestNew_Revalidate(t *testing.T) {
+	s := &testServer{now: time.Now()}
+	s.handle = func(r *http.Request, res *http.Response) {
+		res.Header.Set("Cache-Control", "no-cache")
+		res.Header.Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			res.StatusCode = http.StatusNotModified
+			res.Header.Set("X-Version", "2")
+			res.Body = http.NoBody
+		}
+	}
+	h := newHandler(s)
+
+	res := get(t, h)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res = get(t, h)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "1", res.Header.Get(XFromCache))
+	require.Equal(t, "2", res.Header.Get("X-Version"))
+	b, _ := ioutil.ReadAll(res.Body)
+	require.Equal(t, "body", string(b))
+	require.Equal(t, int32(2), s.count())
+
+	res = get(t, h, "If-None-Match", `"v1"`)
+	require.Equal(t, http.StatusNotModified, res.StatusCode)
+	require.Equal(t, "", res.Header.Get(XFromCache))
+}
+
+// nolint: bodyclose
+func TestNew_LastModified(t *testing.T) {
+	s := &testServer{now: time.Now()}
+	lm := s.now.Add(-time.Hour * 10).UTC().Format(http.TimeFormat)
+	s.handle = func(r *http.Request, res *http.Response) {
+		res.Header.Set("Last-Modified", lm)
+		if r.Header.Get("If-Modified-Since") == lm {
+			res.StatusCode = http.StatusNotModified
+			res.Body = http.NoBody
+		}
+	}
+	h := newHandler(s)
+	get(t, h)
+	s.now = s.now.Add(time.Minute * 59)
+	require.Equal(t, "1", get(t, h).Header.Get(XFromCache))
+	require.Equal(t, int32(1), s.count())
+
+	s.now = s.now.Add(time.Minute * 2)
+	res := get(t, h)
+	require.Equal(t, "1", res.Header.Get(XFromCache))
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, int32(2), s.count())
+}
+
+// nolint: bodyclose
+func TestNew_Vary(t *testing.T) {
+	s := &testServer{now: time.Now(), handle: func(r *http.Request, res *http.Response) {
+		res.Header.Set("Cache-Control", "max-age=60")
+		res.Header.Set("Vary", "Accept")
+	}}
+	h := newHandler(s)
+	get(t, h, "Accept", "application/json")
+	require.Equal(t, "1", get(t, h, "Accept", "application/json").Header.Get(XFromCache))
+	require.Equal(t, "", get(t, h, "Accept", "text/html").Header.Get(XFromCache))
+	require.Equal(t, int32(2), s.count())
+}
+
+// nolint: bodyclose
+func TestNew_StaleWhileRevalidate(t *testing.T) {
+	done := make(chan struct{}, 1)
+	s := &testServer{now: time.Now(), handle: func(r *http.Request, res *http.Response) {
+		res.Header.Set("Cache-Control", "max-age=10, stale-while-revalidate=60")
+	}}
+	h := newHandler(s)
+	get(t, h)
+	s.handle = func(r *http.Request, res *http.Response) {
+		res.Header.Set("Cache-Control", "max-age=10, stale-while-revalidate=60")
+		done <- struct{}{}
+	}
+	s.now = s.now.Add(time.Second * 30)
+	require.Equal(t, "1", get(t, h).Header.Get(XFromCache))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("response not revalidated")
+	}
+	require.Equal(t, int32(2), s.count())
+}
+
+// nolint: bodyclose
+func TestNew_StaleWhileRevalidateOnce(t *testing.T) {
+	release := make(chan struct{})
+	canceled := make(chan error, 1)
+	s := &testServer{now: time.Now(), handle: func(r *http.Request, res *http.Response) {
+		res.Header.Set("Cache-Control", "max-age=10, stale-while-revalidate=60")
+	}}
+	h := newHandler(s)
+	get(t, h)
+	s.handle = func(r *http.Request, res *http.Response) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			canceled <- r.Context().Err()
+		}
+		res.Header.Set("Cache-Control", "max-age=10, stale-while-revalidate=60")
+	}
+	s.now = s.now.Add(time.Second * 30)
+	for i := 0; i < 5; i++ {
+		require.Equal(t, "1", get(t, h).Header.Get(XFromCache))
+	}
+	time.Sleep(time.Millisecond * 20)
+	require.Equal(t, int32(2), s.count(), "one revalidation per key")
+
+	select {
+	case err := <-canceled:
+		require.Equal(t, context.DeadlineExceeded, err)
+	case <-time.After(time.Second * 2):
+		t.Fatal("revalidation not bounded by timeout")
+	}
+	close(release)
+}
+
+// nolint: bodyclose
+func TestNew_StaleIfError(t *testing.T) {
+	s := &testServer{now: time.Now(), handle: func(r *http.Request, res *http.Response) {
+		res.Header.Set("Cache-Control", "max-age=10, stale-if-error=60")
+	}}
+	h := newHandler(s)
+	get(t, h)
+	s.handle = func(r *http.Request, res *http.Response) {
+		res.StatusCode = http.StatusBadGateway
+	}
+	s.now = s.now.Add(time.Second * 30)
+	res := get(t, h)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "1", res.Header.Get(XFromCache))
+
+	s.now = s.now.Add(time.Minute)
+	res = get(t, h)
+	require.Equal(t, http.StatusBadGateway, res.StatusCode)
+}
+
+// nolint: bodyclose
+func TestNew_OnlyIfCached(t *testing.T) {
+	s := &testServer{now: time.Now(), handle: func(r *http.Request, res *http.Response) {}}
+	h := newHandler(s)
+	res := get(t, h, "Cache-Control", "only-if-cached")
+	require.Equal(t, http.StatusGatewayTimeout, res.StatusCode)
+	require.Equal(t, int32(0), s.count())
+}
+
+// nolint: bodyclose
+func TestNew_Invalidate(t *testing.T) {
+	s := &testServer{now: time.Now(), handle: func(r *http.Request, res *http.Response) {
+		res.Header.Set("Cache-Control", "max-age=60")
+	}}
+	h := newHandler(s)
+	get(t, h)
+	require.Equal(t, "1", get(t, h).Header.Get(XFromCache))
+
+	req, err := http.NewRequest(http.MethodPost, "http://google.com/data", nil)
+	require.Nil(t, err)
+	res, err := h(req)
+	require.Nil(t, err)
+	require.Nil(t, res.Body.Close())
+
+	require.Equal(t, "", get(t, h).Header.Get(XFromCache))
+	require.Equal(t, int32(3), s.count())
+}
+
+// nolint: bodyclose
+func TestNew_Error(t *testing.T) {
+	mw := New(NewLRU(10))
+	req, err := http.NewRequest(http.MethodGet, "http://google.com/data", nil)
+	require.Nil(t, err)
+	_, err = mw(req, func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("failed")
+	})
+	require.EqualError(t, err, "failed")
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store of the cached responses
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+}
+
+// NewLRU create in memory store which keeps size last used responses
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// LRU in memory store with least recently used eviction
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type item struct {
+	key   string
+	value []byte
+}
+
+// Get value by key
+func (l *LRU) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.items[key]; ok {
+		l.ll.MoveToFront(el)
+		return el.Value.(*item).value, true
+	}
+
+	return nil, false
+}
+
+// Set value by key and evict oldest when size exceeded
+func (l *LRU) Set(key string, value []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.items[key]; ok {
+		l.ll.MoveToFront(el)
+		el.Value.(*item).value = value
+		return
+	}
+	l.items[key] = l.ll.PushFront(&item{key: key, value: value})
+	for l.size > 0 && l.ll.Len() > l.size {
+		el := l.ll.Back()
+		l.ll.Remove(el)
+		delete(l.items, el.Value.(*item).key)
+	}
+}
+
+// Delete value by key
+func (l *LRU) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.items[key]; ok {
+		l.ll.Remove(el)
+		delete(l.items, key)
+	}
+}
+
+// Len count of the stored values
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.ll.Len()
+}
+
+// NewDisk create store which keeps responses in files of the dir
+func NewDisk(dir string) *Disk {
+	return &Disk{dir: dir}
+}
+
+// Disk store responses on disk
+type Disk struct {
+	dir string
+}
+
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get value by key
+func (d *Disk) Get(key string) ([]byte, bool) {
+	b, err := ioutil.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	return b, true
+}
+
+// Set write value to the file by key
+func (d *Disk) Set(key string, value []byte) {
+	if err := os.MkdirAll(d.dir, 0700); err != nil {
+		return
+	}
+	f, err := ioutil.TempFile(d.dir, ".tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(value)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), d.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
+
+// Delete remove file by key
+func (d *Disk) Delete(key string) {
+	_ = os.Remove(d.path(key))
+}
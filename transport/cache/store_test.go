@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	l := NewLRU(2)
+	l.Set("one", []byte("1"))
+	l.Set("two", []byte("2"))
+	v, ok := l.Get("one")
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), v)
+
+	l.Set("three", []byte("3"))
+	_, ok = l.Get("two")
+	require.False(t, ok)
+	require.Equal(t, 2, l.Len())
+
+	l.Set("one", []byte("11"))
+	v, _ = l.Get("one")
+	require.Equal(t, []byte("11"), v)
+
+	l.Delete("one")
+	_, ok = l.Get("one")
+	require.False(t, ok)
+	require.Equal(t, 1, l.Len())
+}
+
+func TestDisk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	d := NewDisk(dir + "/responses")
+	_, ok := d.Get("http://google.com")
+	require.False(t, ok)
+
+	d.Set("http://google.com", []byte("response"))
+	v, ok := d.Get("http://google.com")
+	require.True(t, ok)
+	require.Equal(t, []byte("response"), v)
+
+	d.Delete("http://google.com")
+	_, ok = d.Get("http://google.com")
+	require.False(t, ok)
+}
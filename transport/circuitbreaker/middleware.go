@@ -0,0 +1,355 @@
+// Package circuitbreaker fail fast requests to the unavailable hosts
+package circuitbreaker
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+// State of the circuit breaker
+type State uint8
+
+// States of the circuit breaker
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	}
+
+	return "unknown"
+}
+
+// Error returned when the breaker rejects request
+type Error struct {
+	Host  string
+	State State
+}
+
+func (e *Error) Error() string {
+	return "circuit breaker: " + e.State.String() + " for the host " + e.Host
+}
+
+type config struct {
+	consecutiveFailures uint
+	ratio               float64
+	minRequests         uint
+	interval            time.Duration
+	coolDown            time.Duration
+	halfOpenRequests    uint
+	key                 func(r *http.Request) string
+	onStateChange       []func(host string, from, to State)
+	checkFailure        []func(res *http.Response) bool
+}
+
+// outcome of the request
+type outcome uint8
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	// outcomeIgnored request canceled by the caller, says nothing about the host
+	outcomeIgnored
+)
+
+func (c *config) outcome(r *http.Request, res *http.Response, err error) outcome {
+	if err != nil {
+		if r.Context().Err() != nil {
+			return outcomeIgnored
+		}
+		return outcomeFailure
+	}
+	if res == nil {
+		return outcomeSuccess
+	}
+	for _, f := range c.checkFailure {
+		if f(res) {
+			return outcomeFailure
+		}
+	}
+
+	return outcomeSuccess
+}
+
+func (c *config) shouldOpen(cnt counts) bool {
+	if c.consecutiveFailures > 0 && cnt.consecutiveFailures >= c.consecutiveFailures {
+		return true
+	}
+
+	return c.ratio > 0 && cnt.requests >= c.minRequests &&
+		float64(cnt.failures)/float64(cnt.requests) >= c.ratio
+}
+
+func (c *config) stateChange(host string, from, to State) {
+	for _, f := range c.onStateChange {
+		f(host, from, to)
+	}
+}
+
+// Option configure circuit breaker
+type Option func(c *config)
+
+// WithConsecutiveFailures open breaker after count failures in a row, zero disable check
+func WithConsecutiveFailures(count uint) Option {
+	return func(c *config) {
+		c.consecutiveFailures = count
+	}
+}
+
+// WithFailureRatio open breaker when the ratio of failures reached and requests at least minRequests
+func WithFailureRatio(ratio float64, minRequests uint) Option {
+	return func(c *config) {
+		c.ratio = ratio
+		c.minRequests = minRequests
+	}
+}
+
+// WithInterval reset counts of the closed breaker by interval, zero never reset
+func WithInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.interval = interval
+	}
+}
+
+// WithCoolDown set duration of the open state before half-open
+func WithCoolDown(coolDown time.Duration) Option {
+	return func(c *config) {
+		c.coolDown = coolDown
+	}
+}
+
+// WithHalfOpenRequests set count of the probe requests in half-open state
+func WithHalfOpenRequests(count uint) Option {
+	return func(c *config) {
+		c.halfOpenRequests = count
+	}
+}
+
+// WithKey set key of the breaker, by default host
+func WithKey(fn func(r *http.Request) string) Option {
+	return func(c *config) {
+		c.key = fn
+	}
+}
+
+// WithOnStateChange add callback on state change
+// the callback called after the breaker released and can send requests through the same breaker
+func WithOnStateChange(fn ...func(host string, from, to State)) Option {
+	return func(c *config) {
+		c.onStateChange = append(c.onStateChange, fn...)
+	}
+}
+
+// WithStatusCode5XX check when status code more or equals 500
+func WithStatusCode5XX() Option {
+	return WithFailure(func(res *http.Response) bool {
+		return res.StatusCode >= 500
+	})
+}
+
+// WithStatusCode set failure codes
+func WithStatusCode(codes ...int) Option {
+	return WithFailure(func(res *http.Response) bool {
+		for _, c := range codes {
+			if res.StatusCode == c {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// WithFailure check response is failure
+// nolint: bodyclose
+func WithFailure(hr ...func(*http.Response) bool) Option {
+	return func(c *config) {
+		c.checkFailure = append(c.checkFailure, hr...)
+	}
+}
+
+// New create new circuit breaker middleware
+func New(opts ...Option) transport.Middleware {
+	cfg := &config{
+		consecutiveFailures: 5,
+		coolDown:            time.Second * 30,
+		halfOpenRequests:    1,
+		key: func(r *http.Request) string {
+			return r.URL.Host
+		},
+	}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	if len(cfg.checkFailure) == 0 {
+		WithStatusCode5XX()(cfg)
+	}
+
+	if cfg.halfOpenRequests == 0 {
+		cfg.halfOpenRequests = 1
+	}
+
+	var breakers sync.Map
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		host := cfg.key(r)
+		b, ok := breakers.Load(host)
+		if !ok {
+			b, _ = breakers.LoadOrStore(host, &breaker{host: host, cfg: cfg})
+		}
+		br := b.(*breaker)
+
+		generation, err := br.before()
+		if err != nil {
+			return nil, err
+		}
+		res, err := n(r)
+		br.after(generation, cfg.outcome(r, res, err))
+
+		return res, err
+	}
+}
+
+type counts struct {
+	requests             uint
+	failures             uint
+	consecutiveFailures  uint
+	consecutiveSuccesses uint
+}
+
+func (c *counts) success() {
+	c.requests++
+	c.consecutiveSuccesses++
+	c.consecutiveFailures = 0
+}
+
+func (c *counts) failure() {
+	c.requests++
+	c.failures++
+	c.consecutiveFailures++
+	c.consecutiveSuccesses = 0
+}
+
+type breaker struct {
+	mu         sync.Mutex
+	host       string
+	cfg        *config
+	state      State
+	generation uint64
+	counts     counts
+	inFlight   uint
+	expiry     time.Time
+	changes    []transition
+}
+
+type transition struct {
+	from, to State
+}
+
+func (b *breaker) before() (uint64, error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.update(time.Now())
+	switch {
+	case b.state == StateOpen:
+		return 0, &Error{Host: b.host, State: b.state}
+	case b.state == StateHalfOpen && b.inFlight >= b.cfg.halfOpenRequests:
+		return 0, &Error{Host: b.host, State: b.state}
+	}
+	b.inFlight++
+
+	return b.generation, nil
+}
+
+func (b *breaker) after(generation uint64, o outcome) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	now := time.Now()
+	b.update(now)
+	if generation != b.generation {
+		return
+	}
+	b.inFlight--
+
+	switch o {
+	case outcomeIgnored:
+		return
+	case outcomeFailure:
+		b.counts.failure()
+		if b.state == StateHalfOpen || b.cfg.shouldOpen(b.counts) {
+			b.setState(StateOpen, now)
+		}
+		return
+	}
+
+	b.counts.success()
+	if b.state == StateHalfOpen && b.counts.consecutiveSuccesses >= b.cfg.halfOpenRequests {
+		b.setState(StateClosed, now)
+	}
+}
+
+func (b *breaker) update(now time.Time) {
+	switch b.state {
+	case StateClosed:
+		if !b.expiry.IsZero() && b.expiry.Before(now) {
+			b.reset(now)
+		}
+	case StateOpen:
+		if b.expiry.Before(now) {
+			b.setState(StateHalfOpen, now)
+		}
+	}
+}
+
+func (b *breaker) setState(state State, now time.Time) {
+	if b.state == state {
+		return
+	}
+	b.changes = append(b.changes, transition{from: b.state, to: state})
+	b.state = state
+	b.reset(now)
+}
+
+// unlock release the breaker and call the callbacks of the state changes made under the lock
+func (b *breaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	for _, c := range changes {
+		b.cfg.stateChange(b.host, c.from, c.to)
+	}
+}
+
+func (b *breaker) reset(now time.Time) {
+	b.generation++
+	b.counts = counts{}
+	b.inFlight = 0
+
+	switch b.state {
+	case StateClosed:
+		b.expiry = time.Time{}
+		if b.cfg.interval > 0 {
+			b.expiry = now.Add(b.cfg.interval)
+		}
+	case StateOpen:
+		b.expiry = now.Add(b.cfg.coolDown)
+	default:
+		b.expiry = time.Time{}
+	}
+}
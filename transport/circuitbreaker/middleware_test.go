@@ -0,0 +1,209 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	mw := New(
+		WithConsecutiveFailures(3),
+		WithCoolDown(time.Second*10),
+		WithStatusCode5XX(),
+		WithStatusCode(http.StatusTooManyRequests),
+		WithOnStateChange(func(host string, from, to State) {
+			log.Printf("circuit breaker %s: %s -> %s", host, from, to)
+		}),
+	)
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, mw),
+	}
+	r, err := cl.Get("http://google.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+var errResp = errors.New("failed get response")
+
+func testRequest(uri string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, uri, nil)
+	return req
+}
+
+func response(code int) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Body:       ioutil.NopCloser(&bytes.Buffer{}),
+	}
+}
+
+// nolint: bodyclose
+func TestNew(t *testing.T) {
+	var changes []State
+	mw := New(
+		WithConsecutiveFailures(2),
+		WithCoolDown(time.Millisecond*50),
+		WithOnStateChange(func(host string, from, to State) {
+			require.Equal(t, "google.com", host)
+			changes = append(changes, to)
+		}),
+	)
+	var cnt int
+	failed := func(*http.Request) (*http.Response, error) {
+		cnt++
+		return nil, errResp
+	}
+	success := func(*http.Request) (*http.Response, error) {
+		cnt++
+		return response(http.StatusOK), nil
+	}
+
+	_, err := mw(testRequest("http://google.com"), failed)
+	require.Equal(t, errResp, err)
+	_, err = mw(testRequest("http://google.com"), failed)
+	require.Equal(t, errResp, err)
+	require.Equal(t, []State{StateOpen}, changes)
+
+	_, err = mw(testRequest("http://google.com"), success)
+	require.EqualError(t, err, "circuit breaker: open for the host google.com")
+	require.IsType(t, &Error{}, err)
+	require.Equal(t, 2, cnt)
+
+	res, err := mw(testRequest("http://ya.ru"), success)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, 3, cnt)
+
+	time.Sleep(time.Millisecond * 60)
+	res, err = mw(testRequest("http://google.com"), success)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, []State{StateOpen, StateHalfOpen, StateClosed}, changes)
+}
+
+// nolint: bodyclose
+func TestWithOnStateChange(t *testing.T) {
+	var (
+		mw     transport.Middleware
+		alerts []error
+	)
+	failed := func(*http.Request) (*http.Response, error) {
+		return nil, errResp
+	}
+	mw = New(WithConsecutiveFailures(1), WithOnStateChange(func(host string, from, to State) {
+		_, err := mw(testRequest("http://"+host+"/alert"), failed)
+		alerts = append(alerts, err)
+	}))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := mw(testRequest("http://google.com"), failed)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.Equal(t, errResp, err)
+	case <-time.After(time.Second):
+		t.Fatal("state change callback blocked the breaker")
+	}
+	require.Equal(t, []error{&Error{Host: "google.com", State: StateOpen}}, alerts)
+}
+
+// nolint: bodyclose
+func TestNew_HalfOpenFailure(t *testing.T) {
+	mw := New(WithConsecutiveFailures(1), WithCoolDown(time.Millisecond*20))
+	_, err := mw(testRequest("http://google.com"), func(*http.Request) (*http.Response, error) {
+		return response(http.StatusServiceUnavailable), nil
+	})
+	require.Nil(t, err)
+
+	time.Sleep(time.Millisecond * 30)
+	res, err := mw(testRequest("http://google.com"), func(*http.Request) (*http.Response, error) {
+		return response(http.StatusBadGateway), nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadGateway, res.StatusCode)
+
+	_, err = mw(testRequest("http://google.com"), func(*http.Request) (*http.Response, error) {
+		return response(http.StatusOK), nil
+	})
+	require.Equal(t, &Error{Host: "google.com", State: StateOpen}, err)
+}
+
+// nolint: bodyclose
+func TestWithFailureRatio(t *testing.T) {
+	mw := New(
+		WithConsecutiveFailures(0),
+		WithFailureRatio(0.5, 4),
+		WithStatusCode(http.StatusConflict),
+	)
+	codes := []int{http.StatusConflict, http.StatusOK, http.StatusInternalServerError, http.StatusConflict}
+	for _, code := range codes {
+		code := code
+		_, err := mw(testRequest("http://google.com"), func(*http.Request) (*http.Response, error) {
+			return response(code), nil
+		})
+		require.Nil(t, err)
+	}
+
+	_, err := mw(testRequest("http://google.com"), func(*http.Request) (*http.Response, error) {
+		return response(http.StatusOK), nil
+	})
+	require.Equal(t, &Error{Host: "google.com", State: StateOpen}, err)
+}
+
+// nolint: bodyclose
+func TestNew_CanceledContext(t *testing.T) {
+	mw := New(WithConsecutiveFailures(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 3; i++ {
+		_, err := mw(testRequest("http://google.com").WithContext(ctx), func(*http.Request) (*http.Response, error) {
+			return nil, context.Canceled
+		})
+		require.Equal(t, context.Canceled, err)
+	}
+
+	var changes []State
+	mw = New(WithConsecutiveFailures(2), WithCoolDown(time.Millisecond*20),
+		WithOnStateChange(func(host string, from, to State) {
+			changes = append(changes, to)
+		}))
+	failed := func(*http.Request) (*http.Response, error) {
+		return nil, errResp
+	}
+	canceled := func(*http.Request) (*http.Response, error) {
+		return nil, context.Canceled
+	}
+
+	_, err := mw(testRequest("http://google.com"), failed)
+	require.Equal(t, errResp, err)
+	_, err = mw(testRequest("http://google.com").WithContext(ctx), canceled)
+	require.Equal(t, context.Canceled, err)
+	_, err = mw(testRequest("http://google.com"), failed)
+	require.Equal(t, errResp, err)
+	require.Equal(t, []State{StateOpen}, changes, "canceled request does not reset consecutive failures")
+
+	time.Sleep(time.Millisecond * 30)
+	_, err = mw(testRequest("http://google.com").WithContext(ctx), canceled)
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, []State{StateOpen, StateHalfOpen}, changes, "canceled probe does not close breaker")
+
+	_, err = mw(testRequest("http://google.com"), failed)
+	require.Equal(t, errResp, err, "canceled probe released half-open slot")
+	require.Equal(t, []State{StateOpen, StateHalfOpen, StateOpen}, changes)
+}
@@ -0,0 +1,277 @@
+package digest
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// ErrChallenge the response has no supported digest challenge
+var ErrChallenge = errors.New("digest: supported challenge not found")
+
+// Algorithms of the digest
+const (
+	AlgorithmMD5        = "MD5"
+	AlgorithmMD5Sess    = "MD5-sess"
+	AlgorithmSHA256     = "SHA-256"
+	AlgorithmSHA256Sess = "SHA-256-sess"
+)
+
+// Challenge of the WWW-Authenticate header
+type Challenge struct {
+	Realm     string
+	Nonce     string
+	Opaque    string
+	Algorithm string
+	QOP       []string
+	Stale     bool
+}
+
+// hash get hash of the algorithm and supported flag
+func (c *Challenge) hash() (func() hash.Hash, bool) {
+	switch strings.ToUpper(strings.TrimSuffix(strings.ToLower(c.Algorithm), "-sess")) {
+	case "", "MD5":
+		return md5.New, true
+	case "SHA-256":
+		return sha256.New, true
+	}
+
+	return nil, false
+}
+
+func (c *Challenge) sess() bool {
+	return strings.HasSuffix(strings.ToLower(c.Algorithm), "-sess")
+}
+
+// qop get supported quality of protection, the empty for the RFC 2069 challenge
+func (c *Challenge) qop() (string, bool) {
+	if len(c.QOP) == 0 {
+		return "", true
+	}
+	for _, q := range c.QOP {
+		if strings.EqualFold(q, "auth") {
+			return "auth", true
+		}
+	}
+
+	return "", false
+}
+
+func (c *Challenge) supported() bool {
+	_, hok := c.hash()
+	_, qok := c.qop()
+
+	return hok && qok && c.Nonce != ""
+}
+
+// Credentials of the authorization
+type Credentials struct {
+	Username string
+	Password string
+	Method   string
+	URI      string
+	Cnonce   string
+	NC       uint32
+}
+
+// Authorization get value of the Authorization header by the challenge
+func (c *Challenge) Authorization(cr Credentials) (string, error) {
+	h, ok := c.hash()
+	qop, qok := c.qop()
+	if !ok || !qok {
+		return "", ErrChallenge
+	}
+	sum := func(parts ...string) string {
+		d := h()
+		_, _ = d.Write([]byte(strings.Join(parts, ":")))
+
+		return hex.EncodeToString(d.Sum(nil))
+	}
+
+	nc := fmt.Sprintf("%08x", cr.NC)
+	ha1 := sum(cr.Username, c.Realm, cr.Password)
+	if c.sess() {
+		ha1 = sum(ha1, c.Nonce, cr.Cnonce)
+	}
+	ha2 := sum(cr.Method, cr.URI)
+
+	var response string
+	if qop == "" {
+		response = sum(ha1, c.Nonce, ha2)
+	} else {
+		response = sum(ha1, c.Nonce, nc, cr.Cnonce, qop, ha2)
+	}
+
+	var b strings.Builder
+	b.WriteString("Digest ")
+	b.WriteString("username=" + quote(cr.Username))
+	b.WriteString(", realm=" + quote(c.Realm))
+	b.WriteString(", nonce=" + quote(c.Nonce))
+	b.WriteString(", uri=" + quote(cr.URI))
+	if c.Algorithm != "" {
+		b.WriteString(", algorithm=" + c.Algorithm)
+	}
+	b.WriteString(", response=" + quote(response))
+	if c.Opaque != "" {
+		b.WriteString(", opaque=" + quote(c.Opaque))
+	}
+	if qop != "" {
+		b.WriteString(", qop=" + qop + ", nc=" + nc + ", cnonce=" + quote(cr.Cnonce))
+	}
+
+	return b.String(), nil
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// ParseChallenges parse digest challenges of the WWW-Authenticate header values
+func ParseChallenges(headers []string) []*Challenge {
+	var challenges []*Challenge
+	for _, h := range headers {
+		var current *Challenge
+		p := parser{s: h}
+		for {
+			p.skip()
+			if p.done() {
+				break
+			}
+			token := p.token()
+			if token == "" {
+				// skip the unexpected character
+				p.pos++
+				continue
+			}
+			p.space()
+			if p.peek() != '=' {
+				// the token without value is a scheme of the new challenge
+				current = nil
+				if strings.EqualFold(token, "Digest") {
+					current = &Challenge{}
+					challenges = append(challenges, current)
+				}
+				continue
+			}
+			p.pos++
+			p.space()
+			value := p.value()
+			if current != nil {
+				current.set(token, value)
+			}
+		}
+	}
+
+	return challenges
+}
+
+// selectChallenge get the strongest supported challenge
+func selectChallenge(challenges []*Challenge) *Challenge {
+	var selected *Challenge
+	for _, c := range challenges {
+		if !c.supported() {
+			continue
+		}
+		if selected == nil || (!strings.HasPrefix(strings.ToUpper(selected.Algorithm), "SHA-256") &&
+			strings.HasPrefix(strings.ToUpper(c.Algorithm), "SHA-256")) {
+			selected = c
+		}
+	}
+
+	return selected
+}
+
+func (c *Challenge) set(name, value string) {
+	switch strings.ToLower(name) {
+	case "realm":
+		c.Realm = value
+	case "nonce":
+		c.Nonce = value
+	case "opaque":
+		c.Opaque = value
+	case "algorithm":
+		c.Algorithm = value
+	case "stale":
+		c.Stale = strings.EqualFold(value, "true")
+	case "qop":
+		for _, q := range strings.Split(value, ",") {
+			if q = strings.TrimSpace(q); q != "" {
+				c.QOP = append(c.QOP, q)
+			}
+		}
+	}
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+
+	return p.s[p.pos]
+}
+
+func (p *parser) space() {
+	for !p.done() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) skip() {
+	for !p.done() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == ',') {
+		p.pos++
+	}
+}
+
+func (p *parser) token() string {
+	start := p.pos
+	for !p.done() && isToken(p.s[p.pos]) {
+		p.pos++
+	}
+
+	return p.s[start:p.pos]
+}
+
+func (p *parser) value() string {
+	if p.peek() != '"' {
+		return p.token()
+	}
+
+	var b strings.Builder
+	for p.pos++; !p.done(); p.pos++ {
+		switch ch := p.s[p.pos]; ch {
+		case '"':
+			p.pos++
+			return b.String()
+		case '\\':
+			if p.pos+1 < len(p.s) {
+				p.pos++
+			}
+			b.WriteByte(p.s[p.pos])
+		default:
+			b.WriteByte(ch)
+		}
+	}
+
+	return b.String()
+}
+
+func isToken(ch byte) bool {
+	if ch <= ' ' || ch >= 0x7f {
+		return false
+	}
+
+	return !strings.ContainsRune(`()<>@,;:\"/[]?={}`, rune(ch))
+}
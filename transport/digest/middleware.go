@@ -0,0 +1,115 @@
+// Package digest authorize requests by the HTTP digest access authentication (RFC 7616)
+package digest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+type config struct {
+	cnonce func() string
+}
+
+// Option configure middleware
+type Option func(c *config)
+
+// WithCnonce set generator of the client nonce, by default random 16 bytes in hex
+func WithCnonce(cnonce func() string) Option {
+	return func(c *config) {
+		c.cnonce = cnonce
+	}
+}
+
+// session of the host with the last challenge and the nonce count
+type session struct {
+	challenge *Challenge
+	nc        uint32
+}
+
+// New create middleware which authorize requests by the digest challenge
+// the challenge cached per host and next requests authorized pre-emptively,
+// the request answered by the challenge resent with the authorization only when its body can be replayed
+func New(username, password string, opts ...Option) transport.Middleware {
+	cfg := config{
+		cnonce: func() string {
+			b := make([]byte, 16)
+			_, _ = rand.Read(b)
+			return hex.EncodeToString(b)
+		},
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	var mu sync.Mutex
+	sessions := make(map[string]*session)
+
+	authorize := func(r *http.Request) (*http.Request, *Challenge, error) {
+		mu.Lock()
+		s, ok := sessions[r.URL.Host]
+		if !ok {
+			mu.Unlock()
+			return r, nil, nil
+		}
+		s.nc++
+		c, nc := s.challenge, s.nc
+		mu.Unlock()
+
+		value, err := c.Authorization(Credentials{
+			Username: username,
+			Password: password,
+			Method:   r.Method,
+			URI:      r.URL.RequestURI(),
+			Cnonce:   cfg.cnonce(),
+			NC:       nc,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ar := transport.CloneRequest(r)
+		ar.Header.Set("Authorization", value)
+
+		return ar, c, nil
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		ar, used, err := authorize(r)
+		if err != nil {
+			return nil, err
+		}
+		res, err := n(ar)
+		if err != nil || res.StatusCode != http.StatusUnauthorized {
+			return res, err
+		}
+
+		c := selectChallenge(ParseChallenges(res.Header[http.CanonicalHeaderKey("WWW-Authenticate")]))
+		if c == nil || !transport.Replayable(r) {
+			return res, nil
+		}
+		// the same nonce without stale flag means the credentials are rejected
+		if used != nil && !c.Stale && c.Nonce == used.Nonce {
+			return res, nil
+		}
+
+		mu.Lock()
+		sessions[r.URL.Host] = &session{challenge: c}
+		mu.Unlock()
+
+		replay, err := transport.Replay(r)
+		if err != nil {
+			return res, nil
+		}
+		retry, _, err := authorize(replay)
+		if err != nil {
+			return res, nil
+		}
+		transport.Drain(res.Body)
+
+		return n(retry)
+	}
+}
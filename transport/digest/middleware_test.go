@@ -0,0 +1,168 @@
+package digest
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, New("admin", "secret")),
+	}
+	r, err := cl.Get("http://camera.local/snapshot.jpg")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+// test vectors of the RFC 7616 section 3.9.1
+const (
+	testRealm  = "http-auth@example.org"
+	testNonce  = "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v"
+	testOpaque = "FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"
+	testCnonce = "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"
+)
+
+func testCredentials() Credentials {
+	return Credentials{
+		Username: "Mufasa",
+		Password: "Circle of Life",
+		Method:   http.MethodGet,
+		URI:      "/dir/index.html",
+		Cnonce:   testCnonce,
+		NC:       1,
+	}
+}
+
+func TestChallenge_Authorization(t *testing.T) {
+	challenges := ParseChallenges([]string{
+		`Basic realm="basic", Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=SHA-256, nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`,
+		`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=MD5, nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`,
+	})
+	require.Len(t, challenges, 2)
+	require.Equal(t, &Challenge{
+		Realm:     testRealm,
+		Nonce:     testNonce,
+		Opaque:    testOpaque,
+		Algorithm: AlgorithmSHA256,
+		QOP:       []string{"auth", "auth-int"},
+	}, challenges[0])
+	require.Equal(t, challenges[0], selectChallenge(challenges))
+
+	auth, err := challenges[0].Authorization(testCredentials())
+	require.Nil(t, err)
+	require.Equal(t, `Digest username="Mufasa", realm="http-auth@example.org", nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", uri="/dir/index.html", algorithm=SHA-256, response="753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS", qop=auth, nc=00000001, cnonce="f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"`, auth)
+
+	auth, err = challenges[1].Authorization(testCredentials())
+	require.Nil(t, err)
+	require.Contains(t, auth, `response="8ca523f5e9506fed4657c9700eebdbec"`)
+
+	_, err = (&Challenge{Nonce: "nonce", Algorithm: "SHA-512-256"}).Authorization(testCredentials())
+	require.Equal(t, ErrChallenge, err)
+	_, err = (&Challenge{Nonce: "nonce", QOP: []string{"auth-int"}}).Authorization(testCredentials())
+	require.Equal(t, ErrChallenge, err)
+}
+
+func TestChallenge_Sess(t *testing.T) {
+	c := &Challenge{Realm: "testrealm@host.com", Nonce: "dcd98b7102dd2f0e8b11d0f600bfb0c093", Algorithm: AlgorithmMD5Sess, QOP: []string{"auth"}}
+	auth, err := c.Authorization(Credentials{
+		Username: "Mufasa",
+		Password: "Circle Of Life",
+		Method:   http.MethodGet,
+		URI:      "/dir/index.html",
+		Cnonce:   "0a4f113b",
+		NC:       1,
+	})
+	require.Nil(t, err)
+	require.Contains(t, auth, `algorithm=MD5-sess, response="8e3825c57e897f5a0dec6c2d4e5059d0"`)
+}
+
+func TestNew(t *testing.T) {
+	var requests, nonce int32
+	atomic.StoreInt32(&nonce, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		expected := testNonce
+		if atomic.LoadInt32(&nonce) == 2 {
+			expected = "renewed"
+		}
+		c := &Challenge{Realm: testRealm, Nonce: expected, Opaque: testOpaque, Algorithm: AlgorithmSHA256, QOP: []string{"auth"}}
+
+		auth := r.Header.Get("Authorization")
+		got := ParseChallenges([]string{auth})
+		if len(got) == 1 && got[0].Nonce == expected {
+			cnonce := strings.SplitN(strings.SplitN(auth, `cnonce="`, 2)[1], `"`, 2)[0]
+			nc := strings.SplitN(strings.SplitN(auth, `nc=`, 2)[1], `,`, 2)[0]
+			cr := Credentials{Username: "Mufasa", Password: "Circle of Life", Method: r.Method, URI: r.URL.RequestURI(), Cnonce: cnonce}
+			for i := uint32(1); i < 10; i++ {
+				cr.NC = i
+				if v, _ := c.Authorization(cr); v == auth {
+					_, _ = w.Write([]byte(nc))
+					return
+				}
+			}
+		}
+
+		value := `Digest realm="` + c.Realm + `", qop="auth", algorithm=SHA-256, nonce="` + c.Nonce + `", opaque="` + c.Opaque + `"`
+		if len(got) == 1 && got[0].Nonce != expected {
+			value += ", stale=true"
+		}
+		w.Header().Set("WWW-Authenticate", value)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	mw := New("Mufasa", "Circle of Life", WithCnonce(func() string {
+		return testCnonce
+	}))
+	cl := http.Client{Transport: transport.NewMiddleware(http.DefaultTransport, mw)}
+
+	get := func(body string) (int, string) {
+		var res *http.Response
+		var err error
+		if body == "" {
+			res, err = cl.Get(server.URL + "/dir/index.html?q=1")
+		} else {
+			res, err = cl.Post(server.URL+"/dir", "text/plain", strings.NewReader(body))
+		}
+		require.Nil(t, err)
+		defer res.Body.Close()
+		b, err := ioutil.ReadAll(res.Body)
+		require.Nil(t, err)
+
+		return res.StatusCode, string(b)
+	}
+
+	status, nc := get("")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "00000001", nc)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	status, nc = get("body")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "00000002", nc)
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&nonce, 2)
+	status, nc = get("")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "00000001", nc)
+	require.Equal(t, int32(5), atomic.LoadInt32(&requests))
+
+	wrong := http.Client{Transport: transport.NewMiddleware(http.DefaultTransport, New("Mufasa", "wrong"))}
+	res, err := wrong.Get(server.URL)
+	require.Nil(t, err)
+	require.Nil(t, res.Body.Close())
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.Equal(t, int32(7), atomic.LoadInt32(&requests))
+}
@@ -2,4 +2,9 @@ module github.com/go-4devs/httpclient/transport
 
 go 1.12
 
-require github.com/stretchr/testify v1.3.0
+replace github.com/go-4devs/httpclient => ../
+
+require (
+	github.com/go-4devs/httpclient v0.0.2
+	github.com/stretchr/testify v1.3.0
+)
@@ -0,0 +1,280 @@
+// Package hedge send duplicate requests to reduce tail latency
+package hedge
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+type config struct {
+	delay      time.Duration
+	max        uint
+	methods    map[string]bool
+	percentile float64
+	window     int
+	minSamples int
+	isSuccess  func(res *http.Response) bool
+}
+
+// Option configure hedge
+type Option func(c *config)
+
+// WithMaxHedged set count of duplicate requests, by default 1
+func WithMaxHedged(count uint) Option {
+	return func(c *config) {
+		c.max = count
+	}
+}
+
+// WithMethods allow hedge requests with methods, by default GET, HEAD and OPTIONS
+func WithMethods(methods ...string) Option {
+	return func(c *config) {
+		for _, m := range methods {
+			c.methods[m] = true
+		}
+	}
+}
+
+// WithPercentile learn delay by percentile of the latency last window requests,
+// the latency of the request is the latency of the successful attempt from its own start
+// the delay of the New used until collected minSamples
+func WithPercentile(percentile float64, window, minSamples int) Option {
+	return func(c *config) {
+		c.percentile = percentile
+		c.window = window
+		c.minSamples = minSamples
+	}
+}
+
+// WithSuccess check response is success and can be returned, by default status code less 500
+// nolint: bodyclose
+func WithSuccess(fn func(res *http.Response) bool) Option {
+	return func(c *config) {
+		c.isSuccess = fn
+	}
+}
+
+// New create hedge middleware which send duplicate request after delay
+// each duplicate sent with own body by GetBody, the request with body without GetBody is not hedged
+func New(delay time.Duration, opts ...Option) transport.Middleware {
+	cfg := &config{
+		delay: delay,
+		max:   1,
+		methods: map[string]bool{
+			http.MethodGet:     true,
+			http.MethodHead:    true,
+			http.MethodOptions: true,
+		},
+		isSuccess: func(res *http.Response) bool {
+			return res.StatusCode < http.StatusInternalServerError
+		},
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	var lat *latency
+	if cfg.percentile > 0 && cfg.window > 0 {
+		lat = &latency{
+			samples: make([]time.Duration, 0, cfg.window),
+			sorted:  make([]time.Duration, 0, cfg.window),
+		}
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		if !cfg.methods[r.Method] || !transport.Replayable(r) {
+			return n(r)
+		}
+
+		delay := cfg.delay
+		if lat != nil {
+			delay = lat.percentile(cfg.percentile, cfg.minSamples, delay)
+		}
+
+		h := &hedged{
+			cfg:     cfg,
+			next:    n,
+			results: make(chan result, cfg.max+1),
+		}
+		res, err := h.do(r, delay)
+		// the latency of the winner attempt measured from its own start so hedging does not lower the learned delay
+		if lat != nil && err == nil {
+			lat.add(h.latency, cfg.window)
+		}
+
+		return res, err
+	}
+}
+
+type result struct {
+	res     *http.Response
+	err     error
+	cancel  context.CancelFunc
+	i       int
+	latency time.Duration
+}
+
+type hedged struct {
+	cfg      *config
+	next     func(r *http.Request) (*http.Response, error)
+	results  chan result
+	latency  time.Duration
+	launched uint
+	cancels  []context.CancelFunc
+}
+
+func (h *hedged) launch(r *http.Request) bool {
+	ctx, cancel := context.WithCancel(r.Context())
+	req := r.WithContext(ctx)
+	if h.launched > 0 {
+		replay, err := transport.Replay(req)
+		if err != nil {
+			cancel()
+			return false
+		}
+		req = replay
+	}
+	h.launched++
+	h.cancels = append(h.cancels, cancel)
+	i := len(h.cancels) - 1
+
+	go func() {
+		start := time.Now()
+		res, err := h.next(req)
+		h.results <- result{res: res, err: err, cancel: cancel, i: i, latency: time.Since(start)}
+	}()
+
+	return true
+}
+
+func (h *hedged) do(r *http.Request, delay time.Duration) (*http.Response, error) {
+	h.launch(r)
+	pending := 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var failed *result
+	for {
+		select {
+		case <-timer.C:
+			if h.launched <= h.cfg.max && h.launch(r) {
+				pending++
+				timer.Reset(delay)
+			}
+		case res := <-h.results:
+			pending--
+			if failed != nil {
+				closeResult(*failed)
+			}
+			if res.err == nil && h.cfg.isSuccess(res.res) {
+				h.latency = res.latency
+				h.discard(res.i, pending)
+				return winner(res), nil
+			}
+			failed = &res
+			if pending == 0 {
+				if h.launched > h.cfg.max || !h.launch(r) {
+					return winner(res), res.err
+				}
+				failed = nil
+				closeResult(res)
+				pending++
+			}
+		}
+	}
+}
+
+// discard cancel pending requests and close its responses in background
+func (h *hedged) discard(winner, pending int) {
+	if pending == 0 {
+		return
+	}
+	for i, cancel := range h.cancels {
+		if i != winner {
+			cancel()
+		}
+	}
+
+	go func() {
+		for i := 0; i < pending; i++ {
+			closeResult(<-h.results)
+		}
+	}()
+}
+
+func closeResult(res result) {
+	res.cancel()
+	if res.res != nil && res.res.Body != nil {
+		_ = res.res.Body.Close()
+	}
+}
+
+// winner cancel context of the response when body closed
+func winner(res result) *http.Response {
+	if res.res == nil || res.res.Body == nil {
+		res.cancel()
+		return res.res
+	}
+	res.res.Body = &body{ReadCloser: res.res.Body, cancel: res.cancel}
+
+	return res.res
+}
+
+type body struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
+
+type latency struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	sorted  []time.Duration
+	pos     int
+}
+
+func (l *latency) add(d time.Duration, window int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < window {
+		l.samples = append(l.samples, d)
+		return
+	}
+	l.samples[l.pos] = d
+	l.pos = (l.pos + 1) % window
+}
+
+func (l *latency) percentile(p float64, minSamples int, def time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) == 0 || len(l.samples) < minSamples {
+		return def
+	}
+	l.sorted = append(l.sorted[:0], l.samples...)
+	sort.Slice(l.sorted, func(i, j int) bool {
+		return l.sorted[i] < l.sorted[j]
+	})
+	i := int(p * float64(len(l.sorted)-1))
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(l.sorted) {
+		i = len(l.sorted) - 1
+	}
+
+	return l.sorted[i]
+}
@@ -0,0 +1,166 @@
+package hedge
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	mw := New(time.Millisecond*50,
+		WithMaxHedged(2),
+		WithPercentile(0.95, 1000, 100),
+	)
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, mw),
+	}
+	r, err := cl.Get("http://google.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+func testRequest(method string) *http.Request {
+	req, _ := http.NewRequest(method, "http://google.com", bytes.NewBufferString("body"))
+	return req
+}
+
+type testBody struct {
+	*bytes.Buffer
+	closed chan struct{}
+}
+
+func (b testBody) Close() error {
+	b.closed <- struct{}{}
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	var cnt int32
+	closed := make(chan struct{}, 2)
+	mw := New(time.Millisecond * 10)
+	res, err := mw(testRequest(http.MethodGet), func(r *http.Request) (*http.Response, error) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil || string(b) != "body" {
+			t.Errorf("unexpected body %q: %v", b, err)
+		}
+
+		i := atomic.AddInt32(&cnt, 1)
+		if i == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+				t.Error("loser not canceled")
+			}
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       testBody{Buffer: bytes.NewBufferString(string('0' + i)), closed: closed},
+		}, r.Context().Err()
+	})
+	require.Nil(t, err)
+	b, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	require.Equal(t, "2", string(b))
+	require.Nil(t, res.Body.Close())
+	require.Equal(t, int32(2), atomic.LoadInt32(&cnt))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatal("response body not closed")
+		}
+	}
+}
+
+// nolint: bodyclose
+func TestNew_Methods(t *testing.T) {
+	var cnt int32
+	mw := New(time.Millisecond)
+	next := func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&cnt, 1)
+		time.Sleep(time.Millisecond * 20)
+		return nil, errors.New("failed")
+	}
+	_, err := mw(testRequest(http.MethodPost), next)
+	require.EqualError(t, err, "failed")
+	require.Equal(t, int32(1), atomic.LoadInt32(&cnt))
+
+	mw = New(time.Millisecond, WithMethods(http.MethodPost), WithMaxHedged(3))
+	_, err = mw(testRequest(http.MethodPost), next)
+	require.EqualError(t, err, "failed")
+	require.Equal(t, int32(5), atomic.LoadInt32(&cnt))
+}
+
+// nolint: bodyclose
+func TestNew_Failed(t *testing.T) {
+	var cnt int32
+	mw := New(time.Second, WithSuccess(func(res *http.Response) bool {
+		return res.StatusCode == http.StatusOK
+	}))
+	res, err := mw(testRequest(http.MethodGet), func(r *http.Request) (*http.Response, error) {
+		code := http.StatusNotFound
+		if atomic.AddInt32(&cnt, 1) == 2 {
+			code = http.StatusOK
+		}
+		return &http.Response{StatusCode: code, Body: ioutil.NopCloser(&bytes.Buffer{})}, nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, int32(2), atomic.LoadInt32(&cnt))
+}
+
+// nolint: bodyclose
+func TestWithPercentile(t *testing.T) {
+	var cnt int32
+	mw := New(time.Hour, WithPercentile(0.5, 10, 3))
+	next := func(r *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&cnt, 1) == 4 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+				t.Error("request not hedged by the learned delay")
+			}
+			return nil, r.Context().Err()
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}
+	for i := 0; i < 3; i++ {
+		_, err := mw(testRequest(http.MethodGet), next)
+		require.Nil(t, err)
+	}
+
+	res, err := mw(testRequest(http.MethodGet), next)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, int32(5), atomic.LoadInt32(&cnt))
+}
+
+func TestLatency(t *testing.T) {
+	l := &latency{}
+	require.Equal(t, time.Second, l.percentile(0.9, 3, time.Second))
+	for i := 1; i <= 10; i++ {
+		l.add(time.Duration(i)*time.Millisecond, 5)
+	}
+	require.Equal(t, []time.Duration{
+		time.Millisecond * 6,
+		time.Millisecond * 7,
+		time.Millisecond * 8,
+		time.Millisecond * 9,
+		time.Millisecond * 10,
+	}, l.samples)
+	require.Equal(t, time.Millisecond*9, l.percentile(0.9, 3, time.Second))
+	require.Equal(t, time.Millisecond*6, l.percentile(0, 3, time.Second))
+}
@@ -0,0 +1,126 @@
+package hmac
+
+import (
+	"encoding/base64"
+	"hash"
+	"net/http"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+type config struct {
+	parts           []Part
+	separator       string
+	hash            func() hash.Hash
+	encode          func(b []byte) string
+	signatureHeader string
+	timestampHeader string
+	nonceHeader     string
+	keyIDHeader     string
+	keyID           string
+	timestamp       func(t time.Time) string
+	nonce           func() string
+	format          func(signature string) string
+	now             func() time.Time
+}
+
+// Option configure signer
+type Option func(c *config)
+
+// WithParts set parts of the canonical string, by default method, path, query, timestamp and sha256 body digest
+func WithParts(parts ...Part) Option {
+	return func(c *config) {
+		c.parts = parts
+	}
+}
+
+// WithSeparator set separator of the parts, by default new line
+func WithSeparator(sep string) Option {
+	return func(c *config) {
+		c.separator = sep
+	}
+}
+
+// WithHash set hash of the hmac like sha512.New, by default sha256.New
+func WithHash(h func() hash.Hash) Option {
+	return func(c *config) {
+		c.hash = h
+	}
+}
+
+// WithBase64 encode signature and body digest by the base64, by default hex
+func WithBase64() Option {
+	return func(c *config) {
+		c.encode = base64.StdEncoding.EncodeToString
+	}
+}
+
+// WithSignatureHeader set header of the signature, by default X-Signature
+// the format get value of the header by the encoded signature
+func WithSignatureHeader(name string, format func(signature string) string) Option {
+	return func(c *config) {
+		c.signatureHeader = name
+		if format != nil {
+			c.format = format
+		}
+	}
+}
+
+// WithTimestampHeader set header of the timestamp, by default X-Timestamp
+func WithTimestampHeader(name string) Option {
+	return func(c *config) {
+		c.timestampHeader = name
+	}
+}
+
+// WithTimestampFormat set format of the timestamp, by default unix seconds
+func WithTimestampFormat(format func(t time.Time) string) Option {
+	return func(c *config) {
+		c.timestamp = format
+	}
+}
+
+// WithNonceHeader set header of the nonce, by default X-Nonce
+func WithNonceHeader(name string) Option {
+	return func(c *config) {
+		c.nonceHeader = name
+	}
+}
+
+// WithNonce set generator of the nonce, by default random 16 bytes in hex
+func WithNonce(nonce func() string) Option {
+	return func(c *config) {
+		c.nonce = nonce
+	}
+}
+
+// WithKeyID set header with id of the shared secret
+func WithKeyID(header, keyID string) Option {
+	return func(c *config) {
+		c.keyIDHeader = header
+		c.keyID = keyID
+	}
+}
+
+// WithNow set time of the signature, by default time.Now
+func WithNow(now func() time.Time) Option {
+	return func(c *config) {
+		c.now = now
+	}
+}
+
+// New create middleware which sign requests by the shared secret
+// the signing middleware must be the last which changes the signed parts of the request
+func New(secret []byte, opts ...Option) transport.Middleware {
+	s := NewSigner(secret, opts...)
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		sr := transport.CloneRequest(r)
+		if err := s.Sign(sr); err != nil {
+			return nil, err
+		}
+
+		return n(sr)
+	}
+}
@@ -0,0 +1,129 @@
+package hmac
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	mw := New([]byte("secret"),
+		WithParts(Method(), Path(), Query(), Header("Content-Type"), Timestamp(), Nonce(), BodyDigest(sha256.New)),
+		WithHash(sha512.New),
+		WithKeyID("X-Key-Id", "client"),
+	)
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, mw),
+	}
+	r, err := cl.Post("https://example.com/foo", "application/json", strings.NewReader(`{"hello": "world"}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+func testNow() time.Time {
+	return time.Unix(1618884473, 0)
+}
+
+func testHMAC(secret, canonical string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(canonical))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestNew(t *testing.T) {
+	mw := New([]byte("secret"), WithNow(testNow))
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/foo?b=2&a=1&a=0", ioutil.NopCloser(strings.NewReader(`{"hello": "world"}`)))
+	require.Nil(t, err)
+
+	_, err = mw(req, func(r *http.Request) (*http.Response, error) {
+		digest := sha256.Sum256([]byte(`{"hello": "world"}`))
+		canonical := "POST\n/foo\na=1&a=0&b=2\n1618884473\n" + hex.EncodeToString(digest[:])
+		require.Equal(t, testHMAC("secret", canonical), r.Header.Get("X-Signature"))
+		require.Equal(t, "1618884473", r.Header.Get("X-Timestamp"))
+		require.Empty(t, r.Header.Get("X-Nonce"))
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.Nil(t, err)
+		require.Equal(t, `{"hello": "world"}`, string(body))
+
+		return nil, nil
+	})
+	require.Nil(t, err)
+	require.Empty(t, req.Header.Get("X-Signature"))
+}
+
+func TestNew_Options(t *testing.T) {
+	mw := New([]byte("secret"),
+		WithParts(Literal("v1"), Method(), Path(), Header("x-request-id"), Nonce(), Timestamp()),
+		WithSeparator("|"),
+		WithHash(sha512.New),
+		WithBase64(),
+		WithSignatureHeader("Authorization", func(signature string) string {
+			return "HMAC " + signature
+		}),
+		WithTimestampHeader("X-Date"),
+		WithTimestampFormat(func(t time.Time) string {
+			return t.UTC().Format(time.RFC3339)
+		}),
+		WithNonceHeader("X-Request-Nonce"),
+		WithNonce(func() string {
+			return "nonce"
+		}),
+		WithKeyID("X-Key-Id", "client"),
+		WithNow(testNow),
+	)
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.Nil(t, err)
+	req.Header.Add("X-Request-Id", "1")
+	req.Header.Add("X-Request-Id", "2")
+
+	_, err = mw(req, func(r *http.Request) (*http.Response, error) {
+		s := NewSigner([]byte("secret"), WithHash(sha512.New), WithBase64(), WithNow(testNow),
+			WithParts(Literal("v1|GET|/|1,2|nonce|2021-04-20T02:07:53Z")))
+		expected, err := http.NewRequest(r.Method, r.URL.String(), nil)
+		require.Nil(t, err)
+		require.Nil(t, s.Sign(expected))
+
+		require.Equal(t, "HMAC "+expected.Header.Get("X-Signature"), r.Header.Get("Authorization"))
+		require.Equal(t, "2021-04-20T02:07:53Z", r.Header.Get("X-Date"))
+		require.Equal(t, "nonce", r.Header.Get("X-Request-Nonce"))
+		require.Equal(t, "client", r.Header.Get("X-Key-Id"))
+		require.Empty(t, r.Header.Get("X-Signature"))
+
+		return nil, nil
+	})
+	require.Nil(t, err)
+}
+
+func TestSigner_Canonical(t *testing.T) {
+	errPart := errors.New("part")
+	s := NewSigner([]byte("secret"), WithParts(Method(), Func(func(r *http.Request) (string, error) {
+		return "", errPart
+	})))
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.Nil(t, err)
+
+	_, err = s.Canonical(req)
+	require.Equal(t, errPart, err)
+
+	_, err = New([]byte("secret"), WithParts(Func(func(r *http.Request) (string, error) {
+		return "", errPart
+	})))(req, nil)
+	require.Equal(t, errPart, err)
+}
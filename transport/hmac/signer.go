@@ -0,0 +1,182 @@
+// Package hmac sign requests by the hmac of the configurable canonical string
+package hmac
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+// values of the request generated for the signature
+type values struct {
+	now       time.Time
+	timestamp string
+	nonce     string
+	cfg       *config
+}
+
+// Part of the canonical string
+type Part struct {
+	value func(r *http.Request, v *values) (string, error)
+}
+
+// Func part by the value of the request
+func Func(fn func(r *http.Request) (string, error)) Part {
+	return Part{value: func(r *http.Request, v *values) (string, error) {
+		return fn(r)
+	}}
+}
+
+// Literal part with constant value
+func Literal(s string) Part {
+	return Part{value: func(r *http.Request, v *values) (string, error) {
+		return s, nil
+	}}
+}
+
+// Method part by the method of the request
+func Method() Part {
+	return Part{value: func(r *http.Request, v *values) (string, error) {
+		return r.Method, nil
+	}}
+}
+
+// Path part by the escaped path of the request
+func Path() Part {
+	return Part{value: func(r *http.Request, v *values) (string, error) {
+		if p := r.URL.EscapedPath(); p != "" {
+			return p, nil
+		}
+		return "/", nil
+	}}
+}
+
+// Query part by the query of the request sorted by the keys
+func Query() Part {
+	return Part{value: func(r *http.Request, v *values) (string, error) {
+		return r.URL.Query().Encode(), nil
+	}}
+}
+
+// Header part by the values of the header joined by comma
+func Header(name string) Part {
+	name = http.CanonicalHeaderKey(name)
+	return Part{value: func(r *http.Request, v *values) (string, error) {
+		return strings.Join(r.Header[name], ","), nil
+	}}
+}
+
+// Timestamp part by the time of the signature, the timestamp written to the timestamp header
+func Timestamp() Part {
+	return Part{value: func(r *http.Request, v *values) (string, error) {
+		if v.timestamp == "" {
+			v.timestamp = v.cfg.timestamp(v.now)
+		}
+		return v.timestamp, nil
+	}}
+}
+
+// Nonce part by the random value, the nonce written to the nonce header
+func Nonce() Part {
+	return Part{value: func(r *http.Request, v *values) (string, error) {
+		if v.nonce == "" {
+			v.nonce = v.cfg.nonce()
+		}
+		return v.nonce, nil
+	}}
+}
+
+// BodyDigest part by the digest of the body encoded as the signature, the empty body has digest of no data
+// the body hashed by transport.CopyBody so the streamed body kept in the memory until the request sent
+func BodyDigest(h func() hash.Hash) Part {
+	return Part{value: func(r *http.Request, v *values) (string, error) {
+		d := h()
+		if err := transport.CopyBody(d, r); err != nil {
+			return "", err
+		}
+
+		return v.cfg.encode(d.Sum(nil)), nil
+	}}
+}
+
+// NewSigner create signer by the shared secret
+func NewSigner(secret []byte, opts ...Option) *Signer {
+	cfg := &config{
+		parts:           []Part{Method(), Path(), Query(), Timestamp(), BodyDigest(sha256.New)},
+		separator:       "\n",
+		hash:            sha256.New,
+		encode:          hex.EncodeToString,
+		signatureHeader: "X-Signature",
+		timestampHeader: "X-Timestamp",
+		nonceHeader:     "X-Nonce",
+		timestamp: func(t time.Time) string {
+			return strconv.FormatInt(t.Unix(), 10)
+		},
+		nonce: func() string {
+			b := make([]byte, 16)
+			_, _ = rand.Read(b)
+			return hex.EncodeToString(b)
+		},
+		format: func(signature string) string {
+			return signature
+		},
+		now: time.Now,
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return &Signer{secret: secret, cfg: cfg}
+}
+
+// Signer sign requests by the shared secret
+type Signer struct {
+	secret []byte
+	cfg    *config
+}
+
+// Canonical get canonical string of the request, the timestamp and nonce headers set to the request
+func (s *Signer) Canonical(r *http.Request) (string, error) {
+	v := &values{now: s.cfg.now(), cfg: s.cfg}
+	parts := make([]string, len(s.cfg.parts))
+	for i, p := range s.cfg.parts {
+		value, err := p.value(r, v)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = value
+	}
+	if v.timestamp != "" && s.cfg.timestampHeader != "" {
+		r.Header.Set(s.cfg.timestampHeader, v.timestamp)
+	}
+	if v.nonce != "" && s.cfg.nonceHeader != "" {
+		r.Header.Set(s.cfg.nonceHeader, v.nonce)
+	}
+
+	return strings.Join(parts, s.cfg.separator), nil
+}
+
+// Sign request and set signature header
+func (s *Signer) Sign(r *http.Request) error {
+	canonical, err := s.Canonical(r)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(s.cfg.hash, s.secret)
+	_, _ = mac.Write([]byte(canonical))
+	r.Header.Set(s.cfg.signatureHeader, s.cfg.format(s.cfg.encode(mac.Sum(nil))))
+	if s.cfg.keyIDHeader != "" {
+		r.Header.Set(s.cfg.keyIDHeader, s.cfg.keyID)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"math/big"
+)
+
+// Algorithms of the signature
+const (
+	AlgorithmHMACSHA256      = "hmac-sha256"
+	AlgorithmEd25519         = "ed25519"
+	AlgorithmECDSAP256SHA256 = "ecdsa-p256-sha256"
+	AlgorithmRSAPSSSHA512    = "rsa-pss-sha512"
+)
+
+// Signer sign the signature base
+type Signer interface {
+	Algorithm() string
+	Sign(base []byte) ([]byte, error)
+}
+
+// Verifier verify signature of the signature base
+type Verifier interface {
+	Algorithm() string
+	Verify(base, signature []byte) error
+}
+
+var (
+	_ Signer   = hmacSHA256(nil)
+	_ Verifier = hmacSHA256(nil)
+	_ Signer   = &ecdsaSigner{}
+	_ Verifier = &ecdsaVerifier{}
+	_ Signer   = &rsaPSSSigner{}
+	_ Verifier = &rsaPSSVerifier{}
+)
+
+// SignVerifier sign and verify by the shared secret
+type SignVerifier interface {
+	Algorithm() string
+	Sign(base []byte) ([]byte, error)
+	Verify(base, signature []byte) error
+}
+
+// NewHMACSHA256 create signer and verifier by the shared secret
+func NewHMACSHA256(key []byte) SignVerifier {
+	return hmacSHA256(key)
+}
+
+type hmacSHA256 []byte
+
+func (hmacSHA256) Algorithm() string {
+	return AlgorithmHMACSHA256
+}
+
+func (k hmacSHA256) Sign(base []byte) ([]byte, error) {
+	h := hmac.New(sha256.New, k)
+	_, _ = h.Write(base)
+
+	return h.Sum(nil), nil
+}
+
+func (k hmacSHA256) Verify(base, signature []byte) error {
+	expected, _ := k.Sign(base)
+	if !hmac.Equal(expected, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// NewECDSAP256Signer create signer by the P-256 private key
+// the signature is concatenation of the r and s values
+func NewECDSAP256Signer(key *ecdsa.PrivateKey) Signer {
+	return &ecdsaSigner{key: key}
+}
+
+type ecdsaSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (*ecdsaSigner) Algorithm() string {
+	return AlgorithmECDSAP256SHA256
+}
+
+func (s *ecdsaSigner) Sign(base []byte) ([]byte, error) {
+	if s.key.Curve != elliptic.P256() {
+		return nil, ErrAlgorithm
+	}
+	digest := sha256.Sum256(base)
+	r, ss, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 64)
+	fillBytes(sig[:32], r)
+	fillBytes(sig[32:], ss)
+
+	return sig, nil
+}
+
+// NewECDSAP256Verifier create verifier by the P-256 public key
+func NewECDSAP256Verifier(key *ecdsa.PublicKey) Verifier {
+	return &ecdsaVerifier{key: key}
+}
+
+type ecdsaVerifier struct {
+	key *ecdsa.PublicKey
+}
+
+func (*ecdsaVerifier) Algorithm() string {
+	return AlgorithmECDSAP256SHA256
+}
+
+func (v *ecdsaVerifier) Verify(base, signature []byte) error {
+	if len(signature) != 64 || v.key.Curve != elliptic.P256() {
+		return ErrInvalidSignature
+	}
+	digest := sha256.Sum256(base)
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(v.key, digest[:], r, s) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// fillBytes set the absolute value of the number to the buffer as a zero-extended big-endian
+func fillBytes(buf []byte, n *big.Int) {
+	b := n.Bytes()
+	for i := range buf[:len(buf)-len(b)] {
+		buf[i] = 0
+	}
+	copy(buf[len(buf)-len(b):], b)
+}
+
+var pssOptions = &rsa.PSSOptions{
+	SaltLength: 64,
+	Hash:       crypto.SHA512,
+}
+
+// NewRSAPSSSigner create signer by the rsa private key
+func NewRSAPSSSigner(key *rsa.PrivateKey) Signer {
+	return &rsaPSSSigner{key: key}
+}
+
+type rsaPSSSigner struct {
+	key *rsa.PrivateKey
+}
+
+func (*rsaPSSSigner) Algorithm() string {
+	return AlgorithmRSAPSSSHA512
+}
+
+func (s *rsaPSSSigner) Sign(base []byte) ([]byte, error) {
+	digest := sha512.Sum512(base)
+
+	return rsa.SignPSS(rand.Reader, s.key, crypto.SHA512, digest[:], pssOptions)
+}
+
+// NewRSAPSSVerifier create verifier by the rsa public key
+func NewRSAPSSVerifier(key *rsa.PublicKey) Verifier {
+	return &rsaPSSVerifier{key: key}
+}
+
+type rsaPSSVerifier struct {
+	key *rsa.PublicKey
+}
+
+func (*rsaPSSVerifier) Algorithm() string {
+	return AlgorithmRSAPSSSHA512
+}
+
+func (v *rsaPSSVerifier) Verify(base, signature []byte) error {
+	digest := sha512.Sum512(base)
+	if err := rsa.VerifyPSS(v.key, crypto.SHA512, digest[:], signature, pssOptions); err != nil {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+//go:build go1.13
+// +build go1.13
+
+package httpsig
+
+import (
+	"crypto/ed25519"
+)
+
+var (
+	_ Signer   = ed25519Signer(nil)
+	_ Verifier = ed25519Verifier(nil)
+)
+
+// NewEd25519Signer create signer by the private key
+func NewEd25519Signer(key ed25519.PrivateKey) Signer {
+	return ed25519Signer(key)
+}
+
+type ed25519Signer ed25519.PrivateKey
+
+func (ed25519Signer) Algorithm() string {
+	return AlgorithmEd25519
+}
+
+func (k ed25519Signer) Sign(base []byte) ([]byte, error) {
+	return ed25519.Sign(ed25519.PrivateKey(k), base), nil
+}
+
+// NewEd25519Verifier create verifier by the public key
+func NewEd25519Verifier(key ed25519.PublicKey) Verifier {
+	return ed25519Verifier(key)
+}
+
+type ed25519Verifier ed25519.PublicKey
+
+func (ed25519Verifier) Algorithm() string {
+	return AlgorithmEd25519
+}
+
+func (k ed25519Verifier) Verify(base, signature []byte) error {
+	if !ed25519.Verify(ed25519.PublicKey(k), base, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+//go:build go1.13
+// +build go1.13
+
+package httpsig
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// test keys of the RFC 9421 appendix B.1.4
+const (
+	testKeyEd25519 = "MC4CAQAwBQYDK2VwBCIEIJ+DYvh6SEqVTm50DFtMDoQikTmiCqirVv9mWG9qfSnF"
+	testPubEd25519 = "MCowBQYDK2VwAyEAJrQLj5P/89iXES9+vFgrIy29clF9CC/oPPsw3c5D0bs="
+)
+
+func TestVerifyRequest_Ed25519(t *testing.T) {
+	der, err := base64.StdEncoding.DecodeString(testKeyEd25519)
+	require.Nil(t, err)
+	priv, err := x509.ParsePKCS8PrivateKey(der)
+	require.Nil(t, err)
+	der, err = base64.StdEncoding.DecodeString(testPubEd25519)
+	require.Nil(t, err)
+	pub, err := x509.ParsePKIXPublicKey(der)
+	require.Nil(t, err)
+
+	mw := New("test-key-ed25519", NewEd25519Signer(priv.(ed25519.PrivateKey)),
+		WithLabel("sig-b26"),
+		WithComponents("date", "@method", "@path", "@authority", "content-type", "content-length"),
+		WithNow(testNow),
+	)
+	_, err = mw(testRequest(t), func(r *http.Request) (*http.Response, error) {
+		require.Equal(t, "sig-b26=:wqcAqbmYJ2ji2glfAMaRy4gruYYnx2nEFN2HN6jrnDnQCK1u02Gb04v9EDgwUPiu4A0w6vuQv5lIp5WPpBKRCw==:",
+			r.Header.Get("Signature"))
+		keys := func(keyID string) (Verifier, error) {
+			return NewEd25519Verifier(pub.(ed25519.PublicKey)), nil
+		}
+		require.Nil(t, VerifyRequest(r, keys, WithVerifyLabel("sig-b26"), WithVerifyNow(testNow)))
+		require.Equal(t, ErrExpired, VerifyRequest(r, keys))
+
+		r.Header.Set("Date", "Tue, 20 Apr 2021 02:07:56 GMT")
+		require.Equal(t, ErrInvalidSignature, VerifyRequest(r, keys, WithVerifyNow(testNow)))
+
+		return nil, nil
+	})
+	require.Nil(t, err)
+}
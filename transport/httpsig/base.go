@@ -0,0 +1,174 @@
+package httpsig
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Errors of the signatures
+var (
+	ErrNoSignature      = errors.New("httpsig: signature not found")
+	ErrInvalidSignature = errors.New("httpsig: invalid signature")
+	ErrMalformed        = errors.New("httpsig: malformed signature header")
+	ErrAlgorithm        = errors.New("httpsig: algorithm mismatch")
+	ErrExpired          = errors.New("httpsig: signature expired")
+	ErrDigest           = errors.New("httpsig: content digest mismatch")
+	ErrNoComponents     = errors.New("httpsig: signature covers no components")
+	ErrNoCreated        = errors.New("httpsig: signature created parameter required")
+)
+
+// ComponentError covered component not found in the message
+type ComponentError struct {
+	Name string
+}
+
+func (e *ComponentError) Error() string {
+	return "httpsig: component " + e.Name + " not found"
+}
+
+// message components of the request or response
+type message struct {
+	method string
+	url    *url.URL
+	host   string
+	header http.Header
+	status int
+}
+
+func requestMessage(r *http.Request) message {
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+
+	return message{method: r.Method, url: r.URL, host: host, header: r.Header}
+}
+
+func responseMessage(r *http.Response) message {
+	return message{header: r.Header, status: r.StatusCode}
+}
+
+// component get value of the component by name
+func (m message) component(name string) (string, error) {
+	if !strings.HasPrefix(name, "@") {
+		values, ok := m.header[http.CanonicalHeaderKey(name)]
+		if !ok {
+			return "", &ComponentError{Name: name}
+		}
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.TrimSpace(v)
+		}
+		return strings.Join(trimmed, ", "), nil
+	}
+
+	if name == "@status" {
+		if m.status == 0 {
+			return "", &ComponentError{Name: name}
+		}
+		return strconv.Itoa(m.status), nil
+	}
+	if m.url == nil {
+		return "", &ComponentError{Name: name}
+	}
+
+	switch name {
+	case "@method":
+		return strings.ToUpper(m.method), nil
+	case "@target-uri":
+		u := *m.url
+		u.Host = m.host
+		return u.String(), nil
+	case "@authority":
+		return authority(m.url.Scheme, m.host), nil
+	case "@scheme":
+		return strings.ToLower(m.url.Scheme), nil
+	case "@request-target":
+		return m.url.RequestURI(), nil
+	case "@path":
+		if p := m.url.EscapedPath(); p != "" {
+			return p, nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + m.url.RawQuery, nil
+	}
+
+	return "", &ComponentError{Name: name}
+}
+
+func authority(scheme, host string) string {
+	host = strings.ToLower(host)
+	h, port, err := net.SplitHostPort(host)
+	if err == nil && ((scheme == "http" && port == "80") || (scheme == "https" && port == "443")) {
+		return h
+	}
+
+	return host
+}
+
+// params of the signature
+type params struct {
+	created int64
+	expires int64
+	keyID   string
+	alg     string
+	nonce   string
+	tag     string
+}
+
+// serialize covered components with params as the value of the Signature-Input
+func serialize(components []string, p params) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for i, c := range components {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.Quote(c))
+	}
+	b.WriteByte(')')
+	if p.created > 0 {
+		b.WriteString(";created=" + strconv.FormatInt(p.created, 10))
+	}
+	if p.expires > 0 {
+		b.WriteString(";expires=" + strconv.FormatInt(p.expires, 10))
+	}
+	if p.keyID != "" {
+		b.WriteString(";keyid=" + strconv.Quote(p.keyID))
+	}
+	if p.alg != "" {
+		b.WriteString(";alg=" + strconv.Quote(p.alg))
+	}
+	if p.nonce != "" {
+		b.WriteString(";nonce=" + strconv.Quote(p.nonce))
+	}
+	if p.tag != "" {
+		b.WriteString(";tag=" + strconv.Quote(p.tag))
+	}
+
+	return b.String()
+}
+
+// signatureBase build base of the signature by the components and serialized params
+func signatureBase(m message, components []string, signatureParams string) ([]byte, error) {
+	var b strings.Builder
+	for _, c := range components {
+		v, err := m.component(c)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(strconv.Quote(c))
+		b.WriteString(": ")
+		b.WriteString(v)
+		b.WriteByte('\n')
+	}
+	b.WriteString(`"@signature-params": `)
+	b.WriteString(signatureParams)
+
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,149 @@
+// Package httpsig sign requests and verify responses by the HTTP message signatures (RFC 9421)
+package httpsig
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+type config struct {
+	label      string
+	components []string
+	digest     bool
+	alg        bool
+	expires    time.Duration
+	nonce      func() string
+	tag        string
+	now        func() time.Time
+}
+
+// Option configure signing
+type Option func(c *config)
+
+// WithLabel set label of the signature, by default sig1
+func WithLabel(label string) Option {
+	return func(c *config) {
+		c.label = label
+	}
+}
+
+// WithComponents set covered components, by default @method, @authority, @path and @query
+// the header components must be set by the request
+func WithComponents(components ...string) Option {
+	return func(c *config) {
+		c.components = components
+	}
+}
+
+// WithContentDigest set sha-256 Content-Digest header of the body and cover it by signature
+func WithContentDigest() Option {
+	return func(c *config) {
+		c.digest = true
+	}
+}
+
+// WithAlgorithm add alg parameter to the signature
+func WithAlgorithm() Option {
+	return func(c *config) {
+		c.alg = true
+	}
+}
+
+// WithExpires add expires parameter to the signature
+func WithExpires(d time.Duration) Option {
+	return func(c *config) {
+		c.expires = d
+	}
+}
+
+// WithNonce add nonce parameter generated for each signature
+func WithNonce(nonce func() string) Option {
+	return func(c *config) {
+		c.nonce = nonce
+	}
+}
+
+// WithTag add tag parameter of the application
+func WithTag(tag string) Option {
+	return func(c *config) {
+		c.tag = tag
+	}
+}
+
+// WithNow set time of the signature, by default time.Now
+func WithNow(now func() time.Time) Option {
+	return func(c *config) {
+		c.now = now
+	}
+}
+
+// New create middleware which sign requests by the key
+func New(keyID string, signer Signer, opts ...Option) transport.Middleware {
+	cfg := &config{
+		label:      "sig1",
+		components: []string{"@method", "@authority", "@path", "@query"},
+		now:        time.Now,
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+	components := cfg.components
+	if cfg.digest {
+		components = append(components[:len(components):len(components)], "content-digest")
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		sr := transport.CloneRequest(r)
+		if cfg.digest {
+			digest, err := contentDigest(sr)
+			if err != nil {
+				return nil, err
+			}
+			sr.Header.Set("Content-Digest", digest)
+		}
+
+		now := cfg.now()
+		p := params{
+			created: now.Unix(),
+			keyID:   keyID,
+			tag:     cfg.tag,
+		}
+		if cfg.expires > 0 {
+			p.expires = now.Add(cfg.expires).Unix()
+		}
+		if cfg.alg {
+			p.alg = signer.Algorithm()
+		}
+		if cfg.nonce != nil {
+			p.nonce = cfg.nonce()
+		}
+
+		input := serialize(components, p)
+		base, err := signatureBase(requestMessage(sr), components, input)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := signer.Sign(base)
+		if err != nil {
+			return nil, err
+		}
+		sr.Header.Add("Signature-Input", cfg.label+"="+input)
+		sr.Header.Add("Signature", cfg.label+"=:"+base64.StdEncoding.EncodeToString(sig)+":")
+
+		return n(sr)
+	}
+}
+
+// contentDigest get sha-256 digest of the body for the Content-Digest header
+func contentDigest(r *http.Request) (string, error) {
+	h := sha256.New()
+	if err := transport.CopyBody(h, r); err != nil {
+		return "", err
+	}
+
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(h.Sum(nil)) + ":", nil
+}
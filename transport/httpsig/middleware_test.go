@@ -0,0 +1,230 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	mw := New("test-key-ecdsa", NewECDSAP256Signer(key),
+		WithComponents("@method", "@authority", "@path", "content-type"),
+		WithContentDigest(),
+	)
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, mw),
+	}
+	r, err := cl.Post("https://example.com/foo", "application/json", strings.NewReader(`{"hello": "world"}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+// test vectors of the RFC 9421 appendix B
+const (
+	testSharedSecret = "uzvJfB4u3N0Jy4T7NZ75MDVcr8zSTInedJtkgcu46YW4XByzNJjxBdtjUkdJPBtbmHhIDi6pcl8jsasjlTMtDQ=="
+	testCreated      = 1618884473
+)
+
+func testRequest(t *testing.T) *http.Request {
+	r, err := http.NewRequest(http.MethodPost, "http://example.com/foo?param=Value&Pet=dog", strings.NewReader(`{"hello": "world"}`))
+	require.Nil(t, err)
+	r.Header.Set("Date", "Tue, 20 Apr 2021 02:07:55 GMT")
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Digest", "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:")
+	r.Header.Set("Content-Length", "18")
+
+	return r
+}
+
+func testNow() time.Time {
+	return time.Unix(testCreated, 0)
+}
+
+func TestNew_HMAC(t *testing.T) {
+	secret, err := base64.StdEncoding.DecodeString(testSharedSecret)
+	require.Nil(t, err)
+	key := NewHMACSHA256(secret)
+
+	mw := New("test-shared-secret", key,
+		WithComponents("date", "@authority", "content-type"),
+		WithNow(testNow),
+	)
+	req := testRequest(t)
+	_, err = mw(req, func(r *http.Request) (*http.Response, error) {
+		require.Equal(t, `sig1=("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`,
+			r.Header.Get("Signature-Input"))
+		require.Equal(t, "sig1=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:", r.Header.Get("Signature"))
+
+		return nil, VerifyRequest(r, func(keyID string) (Verifier, error) {
+			require.Equal(t, "test-shared-secret", keyID)
+			return key, nil
+		}, WithRequiredComponents("@authority"), WithVerifyNow(testNow))
+	})
+	require.Nil(t, err)
+	require.Empty(t, req.Header.Get("Signature"))
+}
+
+func TestNew_RoundTrip(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	cases := []struct {
+		signer   Signer
+		verifier Verifier
+	}{
+		{signer: NewECDSAP256Signer(ecKey), verifier: NewECDSAP256Verifier(&ecKey.PublicKey)},
+		{signer: NewRSAPSSSigner(rsaKey), verifier: NewRSAPSSVerifier(&rsaKey.PublicKey)},
+	}
+	for _, c := range cases {
+		t.Run(c.signer.Algorithm(), func(t *testing.T) {
+			mw := New("key", c.signer, WithAlgorithm(), WithContentDigest(), WithExpires(time.Minute),
+				WithTag("payments"), WithNonce(func() string {
+					return "nonce"
+				}))
+			req, err := http.NewRequest(http.MethodPost, "https://example.com:443/pay?id=1",
+				ioutil.NopCloser(strings.NewReader(`{"amount":1}`)))
+			require.Nil(t, err)
+
+			_, err = mw(req, func(r *http.Request) (*http.Response, error) {
+				require.Equal(t, "sha-256=:wrEeZX4S/RdzWWJ8qJQSAY4idNCHPPv88fxQ9oVYLp4=:", r.Header.Get("Content-Digest"))
+				require.True(t, strings.HasPrefix(r.Header.Get("Signature-Input"),
+					`sig1=("@method" "@authority" "@path" "@query" "content-digest");created=`))
+				require.Contains(t, r.Header.Get("Signature-Input"),
+					`;keyid="key";alg="`+c.signer.Algorithm()+`";nonce="nonce";tag="payments"`)
+
+				keys := func(keyID string) (Verifier, error) {
+					return c.verifier, nil
+				}
+				require.Nil(t, VerifyRequest(r, keys, WithVerifyDigest(), WithVerifyTag("payments"), WithMaxAge(time.Minute)))
+				require.Equal(t, ErrNoSignature, VerifyRequest(r, keys, WithVerifyTag("other")))
+				require.Equal(t, ErrExpired, VerifyRequest(r, keys, WithVerifyNow(func() time.Time {
+					return time.Now().Add(2 * time.Minute)
+				})))
+				require.Equal(t, ErrAlgorithm, VerifyRequest(r, func(keyID string) (Verifier, error) {
+					return NewHMACSHA256([]byte("secret")), nil
+				}))
+
+				r.Body = ioutil.NopCloser(strings.NewReader(`{"amount":2}`))
+				r.GetBody = func() (io.ReadCloser, error) {
+					return ioutil.NopCloser(strings.NewReader(`{"amount":2}`)), nil
+				}
+				require.Equal(t, ErrDigest, VerifyRequest(r, keys, WithVerifyDigest()))
+
+				return nil, nil
+			})
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestNew_MissingComponent(t *testing.T) {
+	mw := New("key", NewHMACSHA256([]byte("secret")), WithComponents("@method", "content-type"))
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.Nil(t, err)
+	_, err = mw(req, func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("not signed")
+	})
+	require.Equal(t, &ComponentError{Name: "content-type"}, err)
+	require.EqualError(t, err, "httpsig: component content-type not found")
+}
+
+func TestNewResponseVerifier(t *testing.T) {
+	key := NewHMACSHA256([]byte("secret"))
+	body := []byte(`{"message": "good dog"}`)
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type":   {"application/json"},
+			"Content-Digest": {"sha-512=:mEWXIS7MaLRuGgxOBdODa3xqM1XdEvxoYhvlCFJ41QJgJc4GTsPp29l5oGX69wWdXymyU0rjJuahq4l5aGgfLQ==:"},
+		},
+	}
+	components := []string{"@status", "content-type", "content-digest"}
+	input := serialize(components, params{created: testCreated, keyID: "test"})
+	base, err := signatureBase(responseMessage(res), components, input)
+	require.Nil(t, err)
+	require.Equal(t, `"@status": 200`+"\n"+
+		`"content-type": application/json`+"\n"+
+		`"content-digest": sha-512=:mEWXIS7MaLRuGgxOBdODa3xqM1XdEvxoYhvlCFJ41QJgJc4GTsPp29l5oGX69wWdXymyU0rjJuahq4l5aGgfLQ==:`+"\n"+
+		`"@signature-params": ("@status" "content-type" "content-digest");created=1618884473;keyid="test"`, string(base))
+	sig, err := key.Sign(base)
+	require.Nil(t, err)
+	res.Header.Set("Signature-Input", `other=("@status");keyid="other", sig1=`+input)
+	res.Header.Set("Signature", "sig1=:"+base64.StdEncoding.EncodeToString(sig)+":, other=:AAAA:")
+
+	verify := NewResponseVerifier(func(keyID string) (Verifier, error) {
+		if keyID != "test" {
+			return nil, errors.New("unknown key")
+		}
+		return key, nil
+	}, WithVerifyDigest(), WithRequiredComponents("@status"), WithVerifyNow(testNow))
+	require.Nil(t, verify(res, bytes.NewBuffer(body)))
+	require.Equal(t, ErrDigest, verify(res, bytes.NewBufferString(`{"message": "bad dog"}`)))
+	require.Equal(t, ErrBodyUnreadable, verify(res, strings.NewReader(string(body))))
+
+	res.StatusCode = http.StatusCreated
+	require.Equal(t, ErrInvalidSignature, verify(res, bytes.NewBuffer(body)))
+
+	res.Header.Del("Signature-Input")
+	require.Equal(t, ErrNoSignature, verify(res, bytes.NewBuffer(body)))
+}
+
+func TestVerify_Unbound(t *testing.T) {
+	key := NewHMACSHA256([]byte("secret"))
+	keys := func(keyID string) (Verifier, error) {
+		return key, nil
+	}
+	sign := func(h http.Header, components []string, p params, m message) {
+		input := serialize(components, p)
+		base, err := signatureBase(m, components, input)
+		require.Nil(t, err)
+		sig, err := key.Sign(base)
+		require.Nil(t, err)
+		h.Set("Signature-Input", "sig1="+input)
+		h.Set("Signature", "sig1=:"+base64.StdEncoding.EncodeToString(sig)+":")
+	}
+
+	res := &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{"Content-Type": {"text/plain"}}}
+	verify := NewResponseVerifier(keys, WithVerifyNow(testNow))
+
+	sign(res.Header, nil, params{created: testCreated, keyID: "k"}, responseMessage(res))
+	require.Equal(t, ErrNoComponents, verify(res, bytes.NewBuffer(nil)))
+
+	sign(res.Header, []string{"content-type"}, params{created: testCreated, keyID: "k"}, responseMessage(res))
+	require.Equal(t, &ComponentError{Name: "@status"}, verify(res, bytes.NewBuffer(nil)))
+
+	sign(res.Header, []string{"@status"}, params{keyID: "k"}, responseMessage(res))
+	require.Equal(t, ErrNoCreated, verify(res, bytes.NewBuffer(nil)))
+	require.Nil(t, NewResponseVerifier(keys, WithOptionalCreated())(res, bytes.NewBuffer(nil)))
+
+	req := testRequest(t)
+	sign(req.Header, nil, params{created: testCreated, keyID: "k"}, requestMessage(req))
+	require.Equal(t, ErrNoComponents, VerifyRequest(req, keys, WithVerifyNow(testNow)))
+}
+
+func TestFillBytes(t *testing.T) {
+	buf := []byte{0xff, 0xff, 0xff, 0xff}
+	fillBytes(buf, big.NewInt(0x0102))
+	require.Equal(t, []byte{0, 0, 1, 2}, buf)
+}
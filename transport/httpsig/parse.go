@@ -0,0 +1,174 @@
+package httpsig
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// parseDictionary split structured field dictionary to the raw values by labels
+func parseDictionary(s string) (map[string]string, error) {
+	members := make(map[string]string)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t")
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, ErrMalformed
+		}
+		label := s[:eq]
+		s = s[eq+1:]
+
+		end, err := valueEnd(s)
+		if err != nil {
+			return nil, err
+		}
+		members[label] = strings.TrimSpace(s[:end])
+		s = s[end:]
+		if len(s) > 0 {
+			s = s[1:]
+		}
+	}
+
+	return members, nil
+}
+
+// valueEnd find end of the member value
+func valueEnd(s string) (int, error) {
+	var quoted, bytes bool
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case bytes:
+			bytes = c != ':'
+		case c == '"':
+			quoted = true
+		case c == ':':
+			bytes = true
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			return i, nil
+		}
+	}
+	if quoted || bytes || depth != 0 {
+		return 0, ErrMalformed
+	}
+
+	return len(s), nil
+}
+
+// parseInput parse covered components and params of the signature input
+func parseInput(raw string) ([]string, params, error) {
+	var p params
+	if !strings.HasPrefix(raw, "(") {
+		return nil, p, ErrMalformed
+	}
+	s := raw[1:]
+	var components []string
+	for {
+		s = strings.TrimLeft(s, " ")
+		if strings.HasPrefix(s, ")") {
+			s = s[1:]
+			break
+		}
+		v, rest, err := parseString(s)
+		if err != nil {
+			return nil, p, err
+		}
+		components = append(components, v)
+		s = rest
+	}
+
+	for len(s) > 0 {
+		if s[0] != ';' {
+			return nil, p, ErrMalformed
+		}
+		s = s[1:]
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, p, ErrMalformed
+		}
+		name := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		var err error
+		if strings.HasPrefix(s, `"`) {
+			value, s, err = parseString(s)
+		} else {
+			end := strings.IndexByte(s, ';')
+			if end < 0 {
+				end = len(s)
+			}
+			value, s = s[:end], s[end:]
+		}
+		if err != nil {
+			return nil, p, err
+		}
+
+		switch name {
+		case "created":
+			p.created, err = strconv.ParseInt(value, 10, 64)
+		case "expires":
+			p.expires, err = strconv.ParseInt(value, 10, 64)
+		case "keyid":
+			p.keyID = value
+		case "alg":
+			p.alg = value
+		case "nonce":
+			p.nonce = value
+		case "tag":
+			p.tag = value
+		}
+		if err != nil {
+			return nil, p, ErrMalformed
+		}
+	}
+
+	return components, p, nil
+}
+
+// parseString parse structured field string
+func parseString(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", ErrMalformed
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 == len(s) {
+				return "", "", ErrMalformed
+			}
+			i++
+			b.WriteByte(s[i])
+		case '"':
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", "", ErrMalformed
+}
+
+// parseBytes parse structured field byte sequence
+func parseBytes(raw string) ([]byte, error) {
+	if len(raw) < 2 || raw[0] != ':' || raw[len(raw)-1] != ':' {
+		return nil, ErrMalformed
+	}
+	b, err := base64.StdEncoding.DecodeString(raw[1 : len(raw)-1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return b, nil
+}
@@ -0,0 +1,279 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrBodyUnreadable body of the message can't be read without consuming it
+var ErrBodyUnreadable = errors.New("httpsig: body can't be read for the content digest")
+
+// KeyResolver get verifier by the key id of the signature
+type KeyResolver func(keyID string) (Verifier, error)
+
+type verifyConfig struct {
+	label    string
+	required []string
+	anyOf    []string
+	maxAge   time.Duration
+	created  bool
+	digest   bool
+	tag      string
+	now      func() time.Time
+}
+
+// VerifyOption configure verification
+type VerifyOption func(c *verifyConfig)
+
+// WithVerifyLabel verify only the signature with label, by default any signature
+func WithVerifyLabel(label string) VerifyOption {
+	return func(c *verifyConfig) {
+		c.label = label
+	}
+}
+
+// WithRequiredComponents require components covered by the signature
+func WithRequiredComponents(components ...string) VerifyOption {
+	return func(c *verifyConfig) {
+		c.required = components
+	}
+}
+
+// WithMaxAge reject signatures created earlier than max age, by default 5 minutes, zero disable check
+func WithMaxAge(d time.Duration) VerifyOption {
+	return func(c *verifyConfig) {
+		c.maxAge = d
+	}
+}
+
+// WithOptionalCreated accept signatures without created parameter, the max age checked only by the created
+func WithOptionalCreated() VerifyOption {
+	return func(c *verifyConfig) {
+		c.created = false
+	}
+}
+
+// WithVerifyDigest require covered Content-Digest header and check it by the body
+func WithVerifyDigest() VerifyOption {
+	return func(c *verifyConfig) {
+		c.digest = true
+	}
+}
+
+// WithVerifyTag require tag parameter of the signature
+func WithVerifyTag(tag string) VerifyOption {
+	return func(c *verifyConfig) {
+		c.tag = tag
+	}
+}
+
+// WithVerifyNow set current time of the verification, by default time.Now
+func WithVerifyNow(now func() time.Time) VerifyOption {
+	return func(c *verifyConfig) {
+		c.now = now
+	}
+}
+
+func newVerifyConfig(opts []VerifyOption) *verifyConfig {
+	cfg := &verifyConfig{
+		maxAge:  5 * time.Minute,
+		created: true,
+		now:     time.Now,
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+	if cfg.digest {
+		cfg.required = append(cfg.required[:len(cfg.required):len(cfg.required)], "content-digest")
+	}
+
+	return cfg
+}
+
+// NewResponseVerifier create fetch middleware which verify signature of the response
+// the signature must cover @status or content-digest to be bound to the response
+// the content digest verified only by the buffered body
+func NewResponseVerifier(keys KeyResolver, opts ...VerifyOption) func(*http.Response, io.Reader) error {
+	cfg := newVerifyConfig(opts)
+	cfg.anyOf = []string{"@status", "content-digest"}
+
+	return func(r *http.Response, body io.Reader) error {
+		if err := cfg.verify(responseMessage(r), keys); err != nil {
+			return err
+		}
+		if !cfg.digest {
+			return nil
+		}
+		b, ok := body.(interface{ Bytes() []byte })
+		if !ok {
+			return ErrBodyUnreadable
+		}
+
+		return verifyDigest(r.Header.Get("Content-Digest"), b.Bytes())
+	}
+}
+
+// VerifyRequest verify signature of the request
+// the content digest verified by the GetBody
+func VerifyRequest(r *http.Request, keys KeyResolver, opts ...VerifyOption) error {
+	cfg := newVerifyConfig(opts)
+	if err := cfg.verify(requestMessage(r), keys); err != nil {
+		return err
+	}
+	if !cfg.digest {
+		return nil
+	}
+
+	var b []byte
+	switch {
+	case r.Body == nil || r.Body == http.NoBody:
+	case r.GetBody != nil:
+		body, err := r.GetBody()
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		if b, err = ioutil.ReadAll(body); err != nil {
+			return err
+		}
+	default:
+		return ErrBodyUnreadable
+	}
+
+	return verifyDigest(r.Header.Get("Content-Digest"), b)
+}
+
+func (c *verifyConfig) verify(m message, keys KeyResolver) error {
+	inputs, err := parseDictionary(strings.Join(m.header["Signature-Input"], ", "))
+	if err != nil {
+		return err
+	}
+	signatures, err := parseDictionary(strings.Join(m.header["Signature"], ", "))
+	if err != nil {
+		return err
+	}
+
+	labels := make([]string, 0, len(inputs))
+	for label := range inputs {
+		if c.label == "" || c.label == label {
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		return ErrNoSignature
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		if err = c.verifyLabel(m, keys, inputs[label], signatures[label]); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+func (c *verifyConfig) verifyLabel(m message, keys KeyResolver, input, signature string) error {
+	if signature == "" {
+		return ErrNoSignature
+	}
+	components, p, err := parseInput(input)
+	if err != nil {
+		return err
+	}
+	sig, err := parseBytes(signature)
+	if err != nil {
+		return err
+	}
+
+	if len(components) == 0 {
+		return ErrNoComponents
+	}
+	covered := make(map[string]bool, len(components))
+	for _, name := range components {
+		covered[name] = true
+	}
+	for _, name := range c.required {
+		if !covered[name] {
+			return &ComponentError{Name: name}
+		}
+	}
+	if len(c.anyOf) > 0 {
+		var ok bool
+		for _, name := range c.anyOf {
+			ok = ok || covered[name]
+		}
+		if !ok {
+			return &ComponentError{Name: c.anyOf[0]}
+		}
+	}
+	if c.created && p.created == 0 {
+		return ErrNoCreated
+	}
+	if c.tag != "" && c.tag != p.tag {
+		return ErrNoSignature
+	}
+
+	now := c.now().Unix()
+	if p.expires > 0 && now > p.expires {
+		return ErrExpired
+	}
+	if c.maxAge > 0 && p.created > 0 && now-p.created > int64(c.maxAge/time.Second) {
+		return ErrExpired
+	}
+
+	v, err := keys(p.keyID)
+	if err != nil {
+		return err
+	}
+	if p.alg != "" && p.alg != v.Algorithm() {
+		return ErrAlgorithm
+	}
+	base, err := signatureBase(m, components, input)
+	if err != nil {
+		return err
+	}
+
+	return v.Verify(base, sig)
+}
+
+// verifyDigest check any supported digest of the Content-Digest header
+func verifyDigest(header string, body []byte) error {
+	digests, err := parseDictionary(header)
+	if err != nil {
+		return err
+	}
+
+	var verified bool
+	for alg, raw := range digests {
+		var sum []byte
+		switch alg {
+		case "sha-256":
+			h := sha256.Sum256(body)
+			sum = h[:]
+		case "sha-512":
+			h := sha512.Sum512(body)
+			sum = h[:]
+		default:
+			continue
+		}
+		expected, err := parseBytes(raw)
+		if err != nil || !bytes.Equal(expected, sum) {
+			return ErrDigest
+		}
+		verified = true
+	}
+	if !verified {
+		return ErrDigest
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+// Package logging log requests and responses by the structured events
+package logging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/go-4devs/httpclient/transport/retry"
+)
+
+// Event of the request
+type Event struct {
+	Method         string
+	URL            string
+	Route          string
+	Status         int
+	Duration       time.Duration
+	Bytes          int64
+	Attempt        uint
+	Err            error
+	Timing         httpclient.Timing
+	RequestHeader  http.Header
+	ResponseHeader http.Header
+	RequestBody    []byte
+	ResponseBody   []byte
+}
+
+// Logger log events
+type Logger interface {
+	Log(ctx context.Context, e Event)
+}
+
+// LoggerFunc func as logger
+type LoggerFunc func(ctx context.Context, e Event)
+
+// Log event
+func (f LoggerFunc) Log(ctx context.Context, e Event) {
+	f(ctx, e)
+}
+
+type config struct {
+	headers bool
+	body    int
+	redact  redact
+}
+
+// Option configure logging
+type Option func(c *config)
+
+// WithHeaders log headers of the request and response
+func WithHeaders() Option {
+	return func(c *config) {
+		c.headers = true
+	}
+}
+
+// WithBody log max bytes of the request and response bodies
+func WithBody(max int) Option {
+	return func(c *config) {
+		c.body = max
+	}
+}
+
+// WithRedactHeaders replace values of the headers, by default Authorization, Proxy-Authorization, Cookie and Set-Cookie
+func WithRedactHeaders(names ...string) Option {
+	return func(c *config) {
+		for _, name := range names {
+			c.redact.headers[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// WithRedactQuery replace values of the query parameters
+func WithRedactQuery(params ...string) Option {
+	return func(c *config) {
+		for _, p := range params {
+			c.redact.query[p] = true
+		}
+	}
+}
+
+// WithRedactJSON replace values of the fields in json bodies
+func WithRedactJSON(fields ...string) Option {
+	return func(c *config) {
+		for _, f := range fields {
+			c.redact.fields[f] = true
+		}
+	}
+}
+
+// New create logging middleware
+// the event logged when response body closed
+func New(logger Logger, opts ...Option) transport.Middleware {
+	cfg := &config{
+		redact: redact{
+			headers: map[string]bool{
+				"Authorization":       true,
+				"Proxy-Authorization": true,
+				"Cookie":              true,
+				"Set-Cookie":          true,
+			},
+			query:  make(map[string]bool),
+			fields: make(map[string]bool),
+		},
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		e := Event{
+			Method:  r.Method,
+			URL:     cfg.redact.url(r.URL),
+			Route:   httpclient.Route(r.Context()),
+			Attempt: retry.Attempt(r.Context()),
+			Bytes:   -1,
+		}
+		if cfg.headers {
+			e.RequestHeader = cfg.redact.header(r.Header)
+		}
+		if cfg.body > 0 && r.GetBody != nil {
+			if body, err := r.GetBody(); err == nil {
+				b, _ := ioutil.ReadAll(io.LimitReader(body, int64(cfg.body)))
+				_ = body.Close()
+				e.RequestBody = cfg.redact.json(b)
+			}
+		}
+
+		start := time.Now()
+		res, err := n(r)
+		e.Duration = time.Since(start)
+		e.Err = err
+		if err != nil || res.Body == nil {
+			logger.Log(r.Context(), e)
+			return res, err
+		}
+
+		e.Status = res.StatusCode
+		if cfg.headers {
+			e.ResponseHeader = cfg.redact.header(res.Header)
+		}
+		var timing *httpclient.TimingRecorder
+		if res.Request != nil {
+			timing = httpclient.TimingFrom(res.Request.Context())
+		}
+		res.Body = &body{
+			ReadCloser: res.Body,
+			timing:     timing,
+			start:      start,
+			event:      e,
+			cfg:        cfg,
+			log: func(e Event) {
+				logger.Log(r.Context(), e)
+			},
+		}
+
+		return res, nil
+	}
+}
+
+type body struct {
+	io.ReadCloser
+	timing *httpclient.TimingRecorder
+	start  time.Time
+	event  Event
+	cfg    *config
+	log    func(e Event)
+	once   sync.Once
+	read   int64
+	buf    bytes.Buffer
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if rest := b.cfg.body - b.buf.Len(); rest > 0 && n > 0 {
+		if rest > n {
+			rest = n
+		}
+		b.buf.Write(p[:rest])
+	}
+	if err != nil && err != io.EOF {
+		b.event.Err = err
+	}
+
+	return n, err
+}
+
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.event.Duration = time.Since(b.start)
+		b.event.Bytes = b.read
+		b.event.Timing = b.timing.Timing()
+		if b.buf.Len() > 0 {
+			b.event.ResponseBody = b.cfg.redact.json(b.buf.Bytes())
+		}
+		b.log(b.event)
+	})
+
+	return err
+}
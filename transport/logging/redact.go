@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// Redacted replace value of the secrets
+const Redacted = "REDACTED"
+
+type redact struct {
+	headers map[string]bool
+	query   map[string]bool
+	fields  map[string]bool
+}
+
+func (r redact) url(u *url.URL) string {
+	ru := *u
+	if _, ok := ru.User.Password(); ok {
+		ru.User = url.UserPassword(ru.User.Username(), Redacted)
+	}
+	if len(r.query) > 0 && ru.RawQuery != "" {
+		q := ru.Query()
+		for name := range q {
+			if r.query[name] {
+				q[name] = []string{Redacted}
+			}
+		}
+		ru.RawQuery = q.Encode()
+	}
+
+	return ru.String()
+}
+
+func (r redact) header(h http.Header) http.Header {
+	rh := make(http.Header, len(h))
+	for name, values := range h {
+		if r.headers[name] {
+			rh[name] = []string{Redacted}
+			continue
+		}
+		rh[name] = append([]string(nil), values...)
+	}
+
+	return rh
+}
+
+// json redact fields of the json body, drop body which can not be parsed
+func (r redact) json(b []byte) []byte {
+	if len(r.fields) == 0 {
+		return append([]byte(nil), b...)
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil
+	}
+	rb, err := json.Marshal(r.value(v))
+	if err != nil {
+		return nil
+	}
+
+	return rb
+}
+
+func (r redact) value(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range val {
+			if r.fields[k] {
+				val[k] = Redacted
+				continue
+			}
+			val[k] = r.value(fv)
+		}
+	case []interface{}:
+		for i, iv := range val {
+			val[i] = r.value(iv)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,66 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-4devs/httpclient"
+)
+
+// NewSlogLogger create logger by the slog
+// errors and server errors logged with error level, client errors with warn level
+func NewSlogLogger(l *slog.Logger) Logger {
+	return LoggerFunc(func(ctx context.Context, e Event) {
+		level := slog.LevelInfo
+		switch {
+		case e.Err != nil || e.Status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case e.Status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", e.Method),
+			slog.String("url", e.URL),
+			slog.Int("status", e.Status),
+			slog.Duration("duration", e.Duration),
+			slog.Int64("bytes", e.Bytes),
+		}
+		if e.Route != "" {
+			attrs = append(attrs, slog.String("route", e.Route))
+		}
+		if e.Attempt > 0 {
+			attrs = append(attrs, slog.Uint64("attempt", uint64(e.Attempt)))
+		}
+		if e.Timing != (httpclient.Timing{}) {
+			attrs = append(attrs, slog.Group("timing",
+				slog.Duration("dns", e.Timing.DNS),
+				slog.Duration("connect", e.Timing.Connect),
+				slog.Duration("tls", e.Timing.TLS),
+				slog.Duration("first_byte", e.Timing.FirstByte),
+				slog.Duration("body_read", e.Timing.Body),
+				slog.Bool("reused", e.Timing.Reused),
+			))
+		}
+		if e.Err != nil {
+			attrs = append(attrs, slog.String("error", e.Err.Error()))
+		}
+		if e.RequestHeader != nil {
+			attrs = append(attrs, slog.String("request_header", headerString(e.RequestHeader)))
+		}
+		if e.ResponseHeader != nil {
+			attrs = append(attrs, slog.String("response_header", headerString(e.ResponseHeader)))
+		}
+		if e.RequestBody != nil {
+			attrs = append(attrs, slog.String("request_body", string(e.RequestBody)))
+		}
+		if e.ResponseBody != nil {
+			attrs = append(attrs, slog.String("response_body", string(e.ResponseBody)))
+		}
+		l.LogAttrs(ctx, level, "http client request", attrs...)
+	})
+}
@@ -0,0 +1,36 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+	l.Log(context.Background(), Event{
+		Method:   http.MethodGet,
+		URL:      "http://google.com",
+		Status:   http.StatusNotFound,
+		Duration: time.Second,
+		Bytes:    10,
+	})
+	require.Equal(t, `level=WARN msg="http client request" method=GET url=http://google.com status=404 duration=1s bytes=10`+"\n",
+		buf.String())
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-4devs/httpclient"
+)
+
+// NewStdLogger create logger by the standard log
+func NewStdLogger(l *log.Logger) Logger {
+	return LoggerFunc(func(ctx context.Context, e Event) {
+		msg := "http client: " + e.Method + " " + e.URL
+		if e.Route != "" {
+			msg += " route=" + e.Route
+		}
+		msg += " status=" + strconv.Itoa(e.Status) +
+			" duration=" + e.Duration.String() +
+			" bytes=" + strconv.FormatInt(e.Bytes, 10)
+		if e.Attempt > 0 {
+			msg += " attempt=" + strconv.FormatUint(uint64(e.Attempt), 10)
+		}
+		if e.Timing != (httpclient.Timing{}) {
+			msg += " dns=" + e.Timing.DNS.String() +
+				" connect=" + e.Timing.Connect.String() +
+				" tls=" + e.Timing.TLS.String() +
+				" first_byte=" + e.Timing.FirstByte.String() +
+				" body_read=" + e.Timing.Body.String() +
+				" reused=" + strconv.FormatBool(e.Timing.Reused)
+		}
+		if e.Err != nil {
+			msg += " error=" + strconv.Quote(e.Err.Error())
+		}
+		if e.RequestHeader != nil {
+			msg += " request_header=" + strconv.Quote(headerString(e.RequestHeader))
+		}
+		if e.ResponseHeader != nil {
+			msg += " response_header=" + strconv.Quote(headerString(e.ResponseHeader))
+		}
+		if e.RequestBody != nil {
+			msg += " request_body=" + strconv.Quote(string(e.RequestBody))
+		}
+		if e.ResponseBody != nil {
+			msg += " response_body=" + strconv.Quote(string(e.ResponseBody))
+		}
+		l.Print(msg)
+	})
+}
+
+func headerString(h http.Header) string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + ": " + strings.Join(h[name], ",")
+	}
+
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,130 @@
+// Package metrics collect metrics of the requests labeled by the route template
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
+)
+
+// Labels of the request
+type Labels struct {
+	Host   string
+	Method string
+	Route  string
+	// Status class of the response like 2xx or error when response not received
+	Status string
+}
+
+// Collector collect metrics of the requests
+type Collector interface {
+	// InFlight change count of the requests in flight, labels without status
+	InFlight(l Labels, delta int)
+	// Observe finished request with duration to response and size of read body
+	Observe(l Labels, duration time.Duration, size int64)
+}
+
+// TimingCollector collect timing breakdown of the requests recorded by the timing middleware
+type TimingCollector interface {
+	ObserveTiming(l Labels, t httpclient.Timing)
+}
+
+// StatusClass get class of the status code
+func StatusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "unknown"
+	}
+
+	return string(rune('0'+code/100)) + "xx"
+}
+
+type config struct {
+	route func(r *http.Request) string
+}
+
+// Option configure metrics
+type Option func(c *config)
+
+// WithRoute set route label of the request, by default route template of the request context
+func WithRoute(fn func(r *http.Request) string) Option {
+	return func(c *config) {
+		c.route = fn
+	}
+}
+
+// New create metrics middleware
+// the request observed when response body closed
+func New(collector Collector, opts ...Option) transport.Middleware {
+	cfg := &config{
+		route: func(r *http.Request) string {
+			return httpclient.Route(r.Context())
+		},
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		l := Labels{
+			Host:   r.URL.Host,
+			Method: r.Method,
+			Route:  cfg.route(r),
+		}
+		collector.InFlight(l, 1)
+		start := time.Now()
+		res, err := n(r)
+		duration := time.Since(start)
+		if err != nil || res.Body == nil {
+			collector.InFlight(l, -1)
+			l.Status = "error"
+			if err == nil {
+				l.Status = StatusClass(res.StatusCode)
+			}
+			collector.Observe(l, duration, 0)
+			return res, err
+		}
+
+		res.Body = &body{
+			ReadCloser: res.Body,
+			done: func(size int64) {
+				collector.InFlight(l, -1)
+				l.Status = StatusClass(res.StatusCode)
+				collector.Observe(l, duration, size)
+				if tc, ok := collector.(TimingCollector); ok && res.Request != nil {
+					if rec := httpclient.TimingFrom(res.Request.Context()); rec != nil {
+						tc.ObserveTiming(l, rec.Timing())
+					}
+				}
+			},
+		}
+
+		return res, nil
+	}
+}
+
+type body struct {
+	io.ReadCloser
+	done func(size int64)
+	once sync.Once
+	read int64
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+
+	return n, err
+}
+
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.done(b.read)
+	})
+
+	return err
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"expvar"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	reg := NewRegistry()
+	expvar.Publish("httpclient", reg.Expvar())
+	http.Handle("/metrics", reg)
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, New(reg)),
+	}
+	r, err := cl.Get("http://google.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+type testCollector struct {
+	inFlight map[Labels]int
+	observed []Labels
+	sizes    []int64
+}
+
+func (c *testCollector) InFlight(l Labels, delta int) {
+	c.inFlight[l] += delta
+}
+
+func (c *testCollector) Observe(l Labels, duration time.Duration, size int64) {
+	c.observed = append(c.observed, l)
+	c.sizes = append(c.sizes, size)
+}
+
+func TestNew(t *testing.T) {
+	c := &testCollector{inFlight: make(map[Labels]int)}
+	mw := New(c)
+
+	req, err := http.NewRequest(http.MethodGet, "http://google.com/user/1", nil)
+	require.Nil(t, err)
+	req = req.WithContext(httpclient.WithRoute(req.Context(), "/user/%d"))
+	l := Labels{Host: "google.com", Method: http.MethodGet, Route: "/user/%d"}
+
+	res, err := mw(req, func(r *http.Request) (*http.Response, error) {
+		require.Equal(t, 1, c.inFlight[l])
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("not found")),
+		}, nil
+	})
+	require.Nil(t, err)
+	require.Empty(t, c.observed)
+
+	b, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	require.Equal(t, "not found", string(b))
+	require.Nil(t, res.Body.Close())
+	require.Nil(t, res.Body.Close())
+
+	require.Equal(t, 0, c.inFlight[l])
+	l.Status = "4xx"
+	require.Equal(t, []Labels{l}, c.observed)
+	require.Equal(t, []int64{9}, c.sizes)
+}
+
+func TestNew_Error(t *testing.T) {
+	c := &testCollector{inFlight: make(map[Labels]int)}
+	mw := New(c, WithRoute(func(r *http.Request) string {
+		return "search"
+	}))
+
+	req, err := http.NewRequest(http.MethodPost, "http://google.com/search?q=1", nil)
+	require.Nil(t, err)
+	_, err = mw(req, func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	require.EqualError(t, err, "connection refused")
+
+	l := Labels{Host: "google.com", Method: http.MethodPost, Route: "search"}
+	require.Equal(t, 0, c.inFlight[l])
+	l.Status = "error"
+	require.Equal(t, []Labels{l}, c.observed)
+	require.Equal(t, []int64{0}, c.sizes)
+}
+
+func TestStatusClass(t *testing.T) {
+	require.Equal(t, "2xx", StatusClass(http.StatusOK))
+	require.Equal(t, "3xx", StatusClass(http.StatusFound))
+	require.Equal(t, "5xx", StatusClass(http.StatusBadGateway))
+	require.Equal(t, "unknown", StatusClass(0))
+	require.Equal(t, "unknown", StatusClass(600))
+}
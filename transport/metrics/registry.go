@@ -0,0 +1,309 @@
+package metrics
+
+import (
+	"bytes"
+	"expvar"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+)
+
+var (
+	_ Collector       = &Registry{}
+	_ TimingCollector = &Registry{}
+)
+
+// DefaultDurationBuckets buckets of the request duration in seconds
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultSizeBuckets buckets of the response size in bytes
+var DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+
+type histogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func (h *histogram) observe(bounds []float64, v float64) {
+	if h.buckets == nil {
+		h.buckets = make([]uint64, len(bounds))
+	}
+	for i, b := range bounds {
+		if v <= b {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+type phase struct {
+	Labels
+	name string
+}
+
+type series struct {
+	requests uint64
+	duration histogram
+	size     histogram
+}
+
+// RegistryOption configure registry
+type RegistryOption func(r *Registry)
+
+// WithDurationBuckets set buckets of the duration histogram in seconds
+func WithDurationBuckets(buckets ...float64) RegistryOption {
+	return func(r *Registry) {
+		r.durationBuckets = buckets
+	}
+}
+
+// WithSizeBuckets set buckets of the response size histogram in bytes
+func WithSizeBuckets(buckets ...float64) RegistryOption {
+	return func(r *Registry) {
+		r.sizeBuckets = buckets
+	}
+}
+
+// WithNamespace set prefix of the metric names, by default httpclient
+func WithNamespace(namespace string) RegistryOption {
+	return func(r *Registry) {
+		r.namespace = namespace
+	}
+}
+
+// NewRegistry create in memory collector
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{
+		namespace:       "httpclient",
+		durationBuckets: DefaultDurationBuckets,
+		sizeBuckets:     DefaultSizeBuckets,
+		series:          make(map[Labels]*series),
+		inFlight:        make(map[Labels]int64),
+		phases:          make(map[phase]*histogram),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r
+}
+
+// Registry keeps metrics in memory and export them by expvar and prometheus text format
+type Registry struct {
+	mu              sync.Mutex
+	namespace       string
+	durationBuckets []float64
+	sizeBuckets     []float64
+	series          map[Labels]*series
+	inFlight        map[Labels]int64
+	phases          map[phase]*histogram
+}
+
+// InFlight change count of the requests in flight
+func (r *Registry) InFlight(l Labels, delta int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.inFlight[l] += int64(delta)
+}
+
+// Observe finished request
+func (r *Registry) Observe(l Labels, duration time.Duration, size int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.series[l]
+	if !ok {
+		s = &series{}
+		r.series[l] = s
+	}
+	s.requests++
+	s.duration.observe(r.durationBuckets, duration.Seconds())
+	s.size.observe(r.sizeBuckets, float64(size))
+}
+
+// ObserveTiming observe durations of the dns, connect, tls, first byte and body read phases
+func (r *Registry) ObserveTiming(l Labels, t httpclient.Timing) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{name: "dns", duration: t.DNS},
+		{name: "connect", duration: t.Connect},
+		{name: "tls", duration: t.TLS},
+		{name: "first_byte", duration: t.FirstByte},
+		{name: "body_read", duration: t.Body},
+	} {
+		if p.duration <= 0 {
+			continue
+		}
+		k := phase{Labels: l, name: p.name}
+		h, ok := r.phases[k]
+		if !ok {
+			h = &histogram{}
+			r.phases[k] = h
+		}
+		h.observe(r.durationBuckets, p.duration.Seconds())
+	}
+}
+
+// Expvar get var with snapshot of the metrics
+func (r *Registry) Expvar() expvar.Var {
+	return expvar.Func(func() interface{} {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		requests := make(map[string]interface{}, len(r.series))
+		for l, s := range r.series {
+			requests[key(l)] = map[string]interface{}{
+				"count":            s.requests,
+				"duration_seconds": s.duration.sum,
+				"size_bytes":       s.size.sum,
+			}
+		}
+		inFlight := make(map[string]int64, len(r.inFlight))
+		for l, v := range r.inFlight {
+			inFlight[key(l)] = v
+		}
+
+		return map[string]interface{}{
+			"requests":  requests,
+			"in_flight": inFlight,
+		}
+	})
+}
+
+func key(l Labels) string {
+	k := l.Method + " " + l.Host + l.Route
+	if l.Status != "" {
+		k += " " + l.Status
+	}
+
+	return k
+}
+
+// ServeHTTP write metrics in prometheus text format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(r.Prometheus())
+}
+
+// Prometheus get metrics in prometheus text format
+func (r *Registry) Prometheus() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b bytes.Buffer
+	labels := make([]Labels, 0, len(r.series))
+	for l := range r.series {
+		labels = append(labels, l)
+	}
+	sortLabels(labels)
+
+	name := r.namespace + "_requests_total"
+	header(&b, name, "counter", "Count of the requests.")
+	for _, l := range labels {
+		sample(&b, name, labelString(l, "", ""), float64(r.series[l].requests))
+	}
+
+	name = r.namespace + "_request_duration_seconds"
+	header(&b, name, "histogram", "Duration of the requests to the response in seconds.")
+	for _, l := range labels {
+		writeHistogram(&b, name, l, "", r.durationBuckets, r.series[l].duration)
+	}
+
+	name = r.namespace + "_response_size_bytes"
+	header(&b, name, "histogram", "Size of the read response bodies in bytes.")
+	for _, l := range labels {
+		writeHistogram(&b, name, l, "", r.sizeBuckets, r.series[l].size)
+	}
+
+	if len(r.phases) > 0 {
+		phases := make([]phase, 0, len(r.phases))
+		for p := range r.phases {
+			phases = append(phases, p)
+		}
+		sort.Slice(phases, func(i, j int) bool {
+			return key(phases[i].Labels)+phases[i].name < key(phases[j].Labels)+phases[j].name
+		})
+		name = r.namespace + "_request_phase_duration_seconds"
+		header(&b, name, "histogram", "Duration of the request phases in seconds.")
+		for _, p := range phases {
+			writeHistogram(&b, name, p.Labels, p.name, r.durationBuckets, *r.phases[p])
+		}
+	}
+
+	labels = labels[:0]
+	for l := range r.inFlight {
+		labels = append(labels, l)
+	}
+	sortLabels(labels)
+	name = r.namespace + "_requests_in_flight"
+	header(&b, name, "gauge", "Count of the requests in flight.")
+	for _, l := range labels {
+		sample(&b, name, labelString(l, "", ""), float64(r.inFlight[l]))
+	}
+
+	return b.Bytes()
+}
+
+func sortLabels(labels []Labels) {
+	sort.Slice(labels, func(i, j int) bool {
+		return key(labels[i]) < key(labels[j])
+	})
+}
+
+func header(b *bytes.Buffer, name, typ, help string) {
+	b.WriteString("# HELP " + name + " " + help + "\n")
+	b.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+func sample(b *bytes.Buffer, name, labels string, v float64) {
+	b.WriteString(name)
+	b.WriteString(labels)
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	b.WriteByte('\n')
+}
+
+func writeHistogram(b *bytes.Buffer, name string, l Labels, phase string, bounds []float64, h histogram) {
+	for i, bound := range bounds {
+		var v uint64
+		if h.buckets != nil {
+			v = h.buckets[i]
+		}
+		sample(b, name+"_bucket", labelString(l, phase, strconv.FormatFloat(bound, 'g', -1, 64)), float64(v))
+	}
+	sample(b, name+"_bucket", labelString(l, phase, "+Inf"), float64(h.count))
+	sample(b, name+"_sum", labelString(l, phase, ""), h.sum)
+	sample(b, name+"_count", labelString(l, phase, ""), float64(h.count))
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelString(l Labels, phase, le string) string {
+	s := `{host="` + escaper.Replace(l.Host) +
+		`",method="` + escaper.Replace(l.Method) +
+		`",route="` + escaper.Replace(l.Route) + `"`
+	if l.Status != "" {
+		s += `,status="` + escaper.Replace(l.Status) + `"`
+	}
+	if phase != "" {
+		s += `,phase="` + phase + `"`
+	}
+	if le != "" {
+		s += `,le="` + le + `"`
+	}
+
+	return s + "}"
+}
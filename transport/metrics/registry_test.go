@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Prometheus(t *testing.T) {
+	reg := NewRegistry(WithDurationBuckets(.1, 1), WithSizeBuckets(10, 100))
+	l := Labels{Host: "google.com", Method: http.MethodGet, Route: `/user/"%d"`}
+	reg.InFlight(l, 1)
+	reg.InFlight(l, 1)
+	reg.InFlight(l, -1)
+
+	l.Status = "2xx"
+	reg.Observe(l, 50*time.Millisecond, 5)
+	reg.Observe(l, 500*time.Millisecond, 50)
+
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	labels := `host="google.com",method="GET",route="/user/\"%d\""`
+	require.Equal(t, strings.Join([]string{
+		"# HELP httpclient_requests_total Count of the requests.",
+		"# TYPE httpclient_requests_total counter",
+		`httpclient_requests_total{` + labels + `,status="2xx"} 2`,
+		"# HELP httpclient_request_duration_seconds Duration of the requests to the response in seconds.",
+		"# TYPE httpclient_request_duration_seconds histogram",
+		`httpclient_request_duration_seconds_bucket{` + labels + `,status="2xx",le="0.1"} 1`,
+		`httpclient_request_duration_seconds_bucket{` + labels + `,status="2xx",le="1"} 2`,
+		`httpclient_request_duration_seconds_bucket{` + labels + `,status="2xx",le="+Inf"} 2`,
+		`httpclient_request_duration_seconds_sum{` + labels + `,status="2xx"} 0.55`,
+		`httpclient_request_duration_seconds_count{` + labels + `,status="2xx"} 2`,
+		"# HELP httpclient_response_size_bytes Size of the read response bodies in bytes.",
+		"# TYPE httpclient_response_size_bytes histogram",
+		`httpclient_response_size_bytes_bucket{` + labels + `,status="2xx",le="10"} 1`,
+		`httpclient_response_size_bytes_bucket{` + labels + `,status="2xx",le="100"} 2`,
+		`httpclient_response_size_bytes_bucket{` + labels + `,status="2xx",le="+Inf"} 2`,
+		`httpclient_response_size_bytes_sum{` + labels + `,status="2xx"} 55`,
+		`httpclient_response_size_bytes_count{` + labels + `,status="2xx"} 2`,
+		"# HELP httpclient_requests_in_flight Count of the requests in flight.",
+		"# TYPE httpclient_requests_in_flight gauge",
+		`httpclient_requests_in_flight{` + labels + `} 1`,
+		"",
+	}, "\n"), rec.Body.String())
+}
+
+func TestRegistry_Expvar(t *testing.T) {
+	reg := NewRegistry(WithNamespace("api"))
+	l := Labels{Host: "google.com", Method: http.MethodGet, Route: "/user/%d"}
+	reg.InFlight(l, 1)
+	l.Status = "5xx"
+	reg.Observe(l, time.Second, 10)
+
+	var v struct {
+		Requests map[string]struct {
+			Count    uint64  `json:"count"`
+			Duration float64 `json:"duration_seconds"`
+			Size     float64 `json:"size_bytes"`
+		} `json:"requests"`
+		InFlight map[string]int64 `json:"in_flight"`
+	}
+	require.Nil(t, json.Unmarshal([]byte(reg.Expvar().String()), &v))
+	require.Equal(t, uint64(1), v.Requests["GET google.com/user/%d 5xx"].Count)
+	require.Equal(t, float64(1), v.Requests["GET google.com/user/%d 5xx"].Duration)
+	require.Equal(t, float64(10), v.Requests["GET google.com/user/%d 5xx"].Size)
+	require.Equal(t, int64(1), v.InFlight["GET google.com/user/%d"])
+
+	require.True(t, strings.HasPrefix(string(reg.Prometheus()), "# HELP api_requests_total"))
+}
+
+func TestRegistry_ObserveTiming(t *testing.T) {
+	reg := NewRegistry(WithDurationBuckets(.1))
+	l := Labels{Host: "google.com", Method: http.MethodGet, Route: "/", Status: "2xx"}
+	reg.ObserveTiming(l, httpclient.Timing{
+		Connect:   50 * time.Millisecond,
+		FirstByte: 200 * time.Millisecond,
+		Reused:    false,
+	})
+
+	out := string(reg.Prometheus())
+	labels := `host="google.com",method="GET",route="/",status="2xx"`
+	require.Contains(t, out, "# TYPE httpclient_request_phase_duration_seconds histogram\n"+
+		`httpclient_request_phase_duration_seconds_bucket{`+labels+`,phase="connect",le="0.1"} 1`+"\n")
+	require.Contains(t, out, `httpclient_request_phase_duration_seconds_bucket{`+labels+`,phase="first_byte",le="0.1"} 0`+"\n")
+	require.Contains(t, out, `httpclient_request_phase_duration_seconds_count{`+labels+`,phase="first_byte"} 1`+"\n")
+	require.NotContains(t, out, `phase="dns"`)
+}
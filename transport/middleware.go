@@ -7,45 +7,50 @@ import (
 // Middleware middleware for http.RoundTripper
 type Middleware func(r *http.Request, next func(r *http.Request) (*http.Response, error)) (*http.Response, error)
 
+// Handler do request and get response
+type Handler func(r *http.Request) (*http.Response, error)
+
+// Handle compose middlewares with handler once
+// the composed handler does not allocate by chain on each request
+func Handle(h Handler, mw ...Middleware) Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = bind(mw[i], h)
+	}
+
+	return h
+}
+
+func bind(mw Middleware, next Handler) Handler {
+	return func(r *http.Request) (*http.Response, error) {
+		return mw(r, next)
+	}
+}
+
 // NewMiddleware create middleware for the transport
-func NewMiddleware(init http.RoundTripper, mw Middleware) http.RoundTripper {
+func NewMiddleware(init http.RoundTripper, mw ...Middleware) http.RoundTripper {
 	return &middleware{
-		init: init,
-		mw:   mw,
+		handle: Handle(init.RoundTrip, mw...),
 	}
 }
 
 // Middleware middleware by init transport
 type middleware struct {
-	init http.RoundTripper
-	mw   Middleware
+	handle Handler
 }
 
 func (tm *middleware) RoundTrip(r *http.Request) (*http.Response, error) {
-	return tm.mw(r, tm.init.RoundTrip)
+	return tm.handle(r)
 }
 
 // Chain transport middleware
+// the next of the each middleware does not share state and can be called concurrently
+// chain allocates next on each call because next of the chain is known only by the call,
+// use Handle or NewMiddleware to compose once without allocations per request
 func Chain(handleFunc ...Middleware) Middleware {
 	n := len(handleFunc)
 	if n > 1 {
-		lastI := n - 1
 		return func(r *http.Request, next func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
-			var (
-				chainHandler func(r *http.Request) (*http.Response, error)
-				curI         int
-			)
-			chainHandler = func(currentRequest *http.Request) (*http.Response, error) {
-				if curI == lastI {
-					return next(currentRequest)
-				}
-				curI++
-				res, err := handleFunc[curI](currentRequest, chainHandler)
-				curI--
-				return res, err
-
-			}
-			return handleFunc[0](r, chainHandler)
+			return handleFunc[0](r, chain(handleFunc[1:], next))
 		}
 	}
 
@@ -57,3 +62,14 @@ func Chain(handleFunc ...Middleware) Middleware {
 		return next(r)
 	}
 }
+
+func chain(handleFunc []Middleware,
+	next func(r *http.Request) (*http.Response, error)) func(r *http.Request) (*http.Response, error) {
+	if len(handleFunc) == 0 {
+		return next
+	}
+
+	return func(r *http.Request) (*http.Response, error) {
+		return handleFunc[0](r, chain(handleFunc[1:], next))
+	}
+}
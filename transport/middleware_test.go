@@ -3,6 +3,7 @@ package transport
 import (
 	"errors"
 	"net/http"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -51,3 +52,110 @@ func TestNewMiddleware(t *testing.T) {
 
 	testErr(t, "")(mw.RoundTrip(nil))
 }
+
+// nolint: bodyclose
+func TestChain_Concurrent(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.Nil(t, err)
+	concurrent := func(r *http.Request, next func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		var wg sync.WaitGroup
+		errs := make([]error, 10)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = next(r)
+			}(i)
+		}
+		wg.Wait()
+		for _, e := range errs[1:] {
+			require.Equal(t, errs[0], e)
+		}
+		return nil, errs[0]
+	}
+	handleErr := func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("err handle")
+	}
+
+	testErr(t, " three two")(Chain(
+		concurrent,
+		testMW(" two"),
+		testMW(" three"),
+	)(r, handleErr))
+	testErr(t, " three two one")(Chain(
+		testMW(" one"),
+		concurrent,
+		testMW(" two"),
+		concurrent,
+		testMW(" three"),
+	)(r, handleErr))
+}
+
+// nolint: bodyclose
+func TestHandle(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.Nil(t, err)
+	handleErr := func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("err handle")
+	}
+	testErr(t, "")(Handle(handleErr)(r))
+	testErr(t, " three two one")(Handle(handleErr,
+		testMW(" one"),
+		testMW(" two"),
+		testMW(" three"),
+	)(r))
+}
+
+var (
+	benchResponse = &http.Response{}
+	benchHandler  = func(r *http.Request) (*http.Response, error) {
+		return benchResponse, nil
+	}
+	benchMiddleware = func(r *http.Request, next func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		return next(r)
+	}
+)
+
+type benchRoundTrip struct{}
+
+func (benchRoundTrip) RoundTrip(r *http.Request) (*http.Response, error) {
+	return benchHandler(r)
+}
+
+func TestHandle_Allocs(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.Nil(t, err)
+	h := Handle(benchHandler, benchMiddleware, benchMiddleware, benchMiddleware)
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = h(r)
+	})
+	require.Equal(t, float64(0), allocs)
+
+	rt := NewMiddleware(benchRoundTrip{}, benchMiddleware, benchMiddleware)
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _ = rt.RoundTrip(r)
+	})
+	require.Equal(t, float64(0), allocs)
+}
+
+func BenchmarkHandle(b *testing.B) {
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+	h := Handle(benchHandler, benchMiddleware, benchMiddleware, benchMiddleware)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = h(r)
+	}
+}
+
+func BenchmarkChain(b *testing.B) {
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+	mw := Chain(benchMiddleware, benchMiddleware, benchMiddleware)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = mw(r, benchHandler)
+	}
+}
@@ -0,0 +1,201 @@
+// Package ratelimit limit requests per second by token buckets
+package ratelimit
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
+)
+
+type route struct {
+	method string
+	path   string
+	limit  limit
+}
+
+type limit struct {
+	rate  float64
+	burst int
+}
+
+type config struct {
+	global  *limit
+	perHost *limit
+	hosts   map[string]limit
+	routes  []route
+}
+
+// Option configure rate limiter
+type Option func(c *config)
+
+// WithGlobal limit all requests by rate per second with burst
+func WithGlobal(rate float64, burst int) Option {
+	return func(c *config) {
+		c.global = &limit{rate: rate, burst: burst}
+	}
+}
+
+// WithPerHost limit requests to each host by rate per second with burst
+func WithPerHost(rate float64, burst int) Option {
+	return func(c *config) {
+		c.perHost = &limit{rate: rate, burst: burst}
+	}
+}
+
+// WithHost limit requests to the host by rate per second with burst, override per host limit
+func WithHost(host string, rate float64, burst int) Option {
+	return func(c *config) {
+		if c.hosts == nil {
+			c.hosts = make(map[string]limit)
+		}
+		c.hosts[host] = limit{rate: rate, burst: burst}
+	}
+}
+
+// WithRoute limit requests by method and url path or route template of the request context like /user/%d,
+// empty method match any method
+func WithRoute(method, path string, rate float64, burst int) Option {
+	return func(c *config) {
+		c.routes = append(c.routes, route{
+			method: method,
+			path:   path,
+			limit:  limit{rate: rate, burst: burst},
+		})
+	}
+}
+
+// New create new rate limit middleware
+func New(opts ...Option) transport.Middleware {
+	cfg := &config{}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	var (
+		global  *bucket
+		buckets sync.Map
+	)
+
+	if cfg.global != nil {
+		global = newBucket(*cfg.global)
+	}
+
+	load := func(key string, l limit) *bucket {
+		b, ok := buckets.Load(key)
+		if !ok {
+			b, _ = buckets.LoadOrStore(key, newBucket(l))
+		}
+		return b.(*bucket)
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		limits := make([]*bucket, 0, 3)
+		if global != nil {
+			limits = append(limits, global)
+		}
+		if l, ok := cfg.hosts[r.URL.Host]; ok {
+			limits = append(limits, load("host "+r.URL.Host, l))
+		} else if cfg.perHost != nil {
+			limits = append(limits, load("host "+r.URL.Host, *cfg.perHost))
+		}
+		template := httpclient.Route(r.Context())
+		for _, rt := range cfg.routes {
+			if (rt.method == "" || rt.method == r.Method) && (rt.path == r.URL.Path || rt.path == template) {
+				limits = append(limits, load("route "+rt.method+" "+rt.path, rt.limit))
+			}
+		}
+
+		if err := wait(r, limits); err != nil {
+			return nil, err
+		}
+
+		return n(r)
+	}
+}
+
+func wait(r *http.Request, limits []*bucket) error {
+	now := time.Now()
+	var delay time.Duration
+	for _, b := range limits {
+		if d := b.reserve(now); d > delay {
+			delay = d
+		}
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-r.Context().Done():
+		for _, b := range limits {
+			b.cancel()
+		}
+		return r.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newBucket(l limit) *bucket {
+	burst := float64(l.burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{
+		rate:   l.rate,
+		burst:  burst,
+		tokens: burst,
+	}
+}
+
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve take token and return duration to wait it
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate <= 0 {
+		return 0
+	}
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	if now.After(b.last) {
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel return reserved token
+func (b *bucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate <= 0 {
+		return
+	}
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	mw := New(
+		WithGlobal(100, 10),
+		WithPerHost(20, 5),
+		WithHost("google.com", 5, 1),
+		WithRoute(http.MethodPost, "/search", 1, 1),
+	)
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, mw),
+	}
+	r, err := cl.Get("http://google.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+func testRequest(method, uri string) *http.Request {
+	req, _ := http.NewRequest(method, uri, nil)
+	return req
+}
+
+func next(cnt *int) func(*http.Request) (*http.Response, error) {
+	return func(*http.Request) (*http.Response, error) {
+		*cnt++
+		return nil, nil
+	}
+}
+
+// nolint: bodyclose
+func TestWithGlobal(t *testing.T) {
+	mw := New(WithGlobal(10, 2))
+	var cnt int
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := mw(testRequest(http.MethodGet, "http://google.com"), next(&cnt))
+		require.Nil(t, err)
+	}
+	require.Equal(t, 3, cnt)
+	require.True(t, time.Since(start) >= time.Millisecond*90)
+}
+
+// nolint: bodyclose
+func TestWithHost(t *testing.T) {
+	mw := New(WithPerHost(1, 1), WithHost("ya.ru", 1000, 10))
+	var cnt int
+	_, err := mw(testRequest(http.MethodGet, "http://google.com"), next(&cnt))
+	require.Nil(t, err)
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		_, err = mw(testRequest(http.MethodGet, "http://ya.ru"), next(&cnt))
+		require.Nil(t, err)
+	}
+	_, err = mw(testRequest(http.MethodGet, "http://go.dev"), next(&cnt))
+	require.Nil(t, err)
+	require.True(t, time.Since(start) < time.Millisecond*100)
+	require.Equal(t, 12, cnt)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err = mw(testRequest(http.MethodGet, "http://google.com").WithContext(ctx), next(&cnt))
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, 12, cnt)
+}
+
+// nolint: bodyclose
+func TestWithRoute(t *testing.T) {
+	mw := New(WithRoute(http.MethodPost, "/search", 1, 1))
+	var cnt int
+	_, err := mw(testRequest(http.MethodPost, "http://google.com/search"), next(&cnt))
+	require.Nil(t, err)
+
+	start := time.Now()
+	_, err = mw(testRequest(http.MethodGet, "http://google.com/search"), next(&cnt))
+	require.Nil(t, err)
+	_, err = mw(testRequest(http.MethodPost, "http://google.com/"), next(&cnt))
+	require.Nil(t, err)
+	require.True(t, time.Since(start) < time.Millisecond*100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*10, cancel)
+	_, err = mw(testRequest(http.MethodPost, "http://google.com/search").WithContext(ctx), next(&cnt))
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 3, cnt)
+}
+
+func TestWithRoute_Template(t *testing.T) {
+	mw := New(WithRoute(http.MethodGet, "/user/%d", 1, 1))
+	route := func(ctx context.Context, uri string) *http.Request {
+		return testRequest(http.MethodGet, uri).WithContext(httpclient.WithRoute(ctx, "/user/%d"))
+	}
+	var cnt int
+	_, err := mw(route(context.Background(), "http://google.com/user/1"), next(&cnt))
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*10, cancel)
+	_, err = mw(route(ctx, "http://google.com/user/2"), next(&cnt))
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 1, cnt)
+}
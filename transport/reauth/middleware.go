@@ -0,0 +1,155 @@
+// Package reauth re-authenticate and replay requests rejected by the server
+package reauth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+// Error of the re-authentication, the refresh failed or the replayed request rejected again
+type Error struct {
+	// StatusCode of the rejected replay, zero when refresh failed
+	StatusCode int
+	// Err of the refresh
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return "reauth: refresh failed: " + e.Err.Error()
+	}
+
+	return "reauth: request rejected after refresh with status " + strconv.Itoa(e.StatusCode)
+}
+
+// Unwrap get error of the refresh
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Refresh authentication state like login session, token or api key
+type Refresh func(ctx context.Context) error
+
+type config struct {
+	rejected func(res *http.Response) bool
+}
+
+// Option configure middleware
+type Option func(c *config)
+
+// WithRejected set predicate of the response rejected by the authentication, by default status 401
+func WithRejected(rejected func(res *http.Response) bool) Option {
+	return func(c *config) {
+		c.rejected = rejected
+	}
+}
+
+// New create middleware which on the rejected response call refresh once and replay the request
+// concurrent rejected requests share one refresh and wait it until own context done, the middleware which authorize requests by the refreshed state
+// must be inside the reauth, the rejected request which body can't be replayed returned as is after the refresh
+func New(refresh Refresh, opts ...Option) transport.Middleware {
+	cfg := config{
+		rejected: func(res *http.Response) bool {
+			return res.StatusCode == http.StatusUnauthorized
+		},
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	f := &refresher{refresh: refresh}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		sent := atomic.LoadUint64(&f.generation)
+
+		res, err := n(r)
+		if err != nil || !cfg.rejected(res) {
+			return res, err
+		}
+
+		if err = f.reauthenticate(r.Context(), sent); err != nil {
+			transport.Drain(res.Body)
+			return nil, &Error{Err: err}
+		}
+		if !transport.Replayable(r) {
+			return res, nil
+		}
+		replay, err := transport.Replay(r)
+		if err != nil {
+			return res, nil
+		}
+		transport.Drain(res.Body)
+
+		res, err = n(replay)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.rejected(res) {
+			transport.Drain(res.Body)
+			return nil, &Error{StatusCode: res.StatusCode}
+		}
+
+		return res, nil
+	}
+}
+
+type refresher struct {
+	// generation of the refreshed state, first field to be aligned for the atomic access
+	generation uint64
+	refresh    Refresh
+
+	mu   sync.Mutex
+	call *call
+}
+
+type call struct {
+	done     chan struct{}
+	err      error
+	canceled bool
+}
+
+// reauthenticate refresh state once for the requests sent before the last refresh
+// the waiters of the refresh in flight stop by own context
+func (f *refresher) reauthenticate(ctx context.Context, sent uint64) error {
+	for {
+		f.mu.Lock()
+		if atomic.LoadUint64(&f.generation) != sent {
+			f.mu.Unlock()
+			return nil
+		}
+		if c := f.call; c != nil {
+			f.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-c.done:
+			}
+			// the leader request canceled by own context, refresh by the waiter context
+			if c.canceled {
+				continue
+			}
+			return c.err
+		}
+		c := &call{done: make(chan struct{})}
+		f.call = c
+		f.mu.Unlock()
+
+		c.err = f.refresh(ctx)
+		c.canceled = c.err != nil && ctx.Err() != nil
+
+		f.mu.Lock()
+		f.call = nil
+		if c.err == nil {
+			atomic.AddUint64(&f.generation, 1)
+		}
+		f.mu.Unlock()
+		close(c.done)
+
+		return c.err
+	}
+}
@@ -0,0 +1,236 @@
+package reauth
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	var (
+		mu    sync.RWMutex
+		token string
+	)
+	login := func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		token = "fresh"
+		return nil
+	}
+	authorize := func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		mu.RLock()
+		r = r.WithContext(r.Context())
+		r.Header = http.Header{"Authorization": {"Bearer " + token}}
+		mu.RUnlock()
+		return n(r)
+	}
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, New(login), authorize),
+	}
+	r, err := cl.Get("https://example.com/api")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+type session struct {
+	token    int32
+	refresh  int32
+	requests int32
+	bodies   []string
+	mu       sync.Mutex
+}
+
+func (s *session) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&s.requests, 1)
+	b, _ := ioutil.ReadAll(r.Body)
+	s.mu.Lock()
+	s.bodies = append(s.bodies, string(b))
+	s.mu.Unlock()
+	if r.Header.Get("X-Token") != strconv.Itoa(int(atomic.LoadInt32(&s.token))) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
+
+func (s *session) client(refresh Refresh, opts ...Option) *http.Client {
+	var current int32
+	authorize := func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		r = r.WithContext(r.Context())
+		r.Header = http.Header{"X-Token": {strconv.Itoa(int(atomic.LoadInt32(&current)))}}
+		return n(r)
+	}
+	if refresh == nil {
+		refresh = func(ctx context.Context) error {
+			atomic.AddInt32(&s.refresh, 1)
+			time.Sleep(20 * time.Millisecond)
+			atomic.StoreInt32(&current, atomic.LoadInt32(&s.token))
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, New(refresh, opts...), authorize),
+	}
+}
+
+func TestNew(t *testing.T) {
+	s := &session{token: 1}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	cl := s.client(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := cl.Post(server.URL, "text/plain", strings.NewReader("body"))
+			require.Nil(t, err)
+			require.Nil(t, res.Body.Close())
+			require.Equal(t, http.StatusOK, res.StatusCode)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&s.refresh))
+	requests := atomic.LoadInt32(&s.requests)
+	require.True(t, requests > 5 && requests <= 10)
+	for _, b := range s.bodies {
+		require.Equal(t, "body", b)
+	}
+
+	res, err := cl.Get(server.URL)
+	require.Nil(t, err)
+	require.Nil(t, res.Body.Close())
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, int32(1), atomic.LoadInt32(&s.refresh))
+	require.Equal(t, requests+1, atomic.LoadInt32(&s.requests))
+}
+
+func TestNew_Error(t *testing.T) {
+	s := &session{token: 1}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	errLogin := errors.New("login failed")
+	_, err := s.client(func(ctx context.Context) error {
+		return errLogin
+	}).Get(server.URL)
+	require.Equal(t, &Error{Err: errLogin}, err.(*url.Error).Err)
+	require.EqualError(t, err.(*url.Error).Err, "reauth: refresh failed: login failed")
+
+	_, err = s.client(func(ctx context.Context) error {
+		return nil
+	}).Get(server.URL)
+	require.Equal(t, &Error{StatusCode: http.StatusUnauthorized}, err.(*url.Error).Err)
+	require.EqualError(t, err.(*url.Error).Err, "reauth: request rejected after refresh with status 401")
+}
+
+func TestNew_Rejected(t *testing.T) {
+	s := &session{token: 1}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	cl := s.client(nil, WithRejected(func(res *http.Response) bool {
+		return res.StatusCode == http.StatusForbidden
+	}))
+	res, err := cl.Get(server.URL)
+	require.Nil(t, err)
+	require.Nil(t, res.Body.Close())
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.Equal(t, int32(0), atomic.LoadInt32(&s.refresh))
+}
+
+func rejectedNext(r *http.Request) (*http.Response, error) {
+	status := http.StatusOK
+	if r.Header.Get("X-Rejected") != "" {
+		status = http.StatusUnauthorized
+	}
+
+	return &http.Response{StatusCode: status, Body: http.NoBody, Request: r}, nil
+}
+
+func rejectedRequest(ctx context.Context) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	r.Header.Set("X-Rejected", "true")
+
+	return r
+}
+
+// nolint: bodyclose
+func TestNew_Refreshing(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	mw := New(func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := mw(rejectedRequest(context.Background()), rejectedNext)
+		done <- err
+	}()
+	<-started
+
+	res, err := mw(httptest.NewRequest(http.MethodGet, "/", nil), rejectedNext)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, "the request sent during the refresh does not wait it")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = mw(rejectedRequest(ctx), rejectedNext)
+	require.Equal(t, &Error{Err: context.Canceled}, err, "the waiter of the refresh stopped by own context")
+
+	close(release)
+	require.Equal(t, &Error{StatusCode: http.StatusUnauthorized}, <-done)
+}
+
+// nolint: bodyclose
+func TestNew_RefreshCanceled(t *testing.T) {
+	var calls int32
+	started := make(chan struct{})
+	mw := New(func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := mw(rejectedRequest(ctx), rejectedNext)
+		done <- err
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := mw(rejectedRequest(context.Background()), rejectedNext)
+		waiter <- err
+	}()
+	cancel()
+	require.Equal(t, &Error{Err: context.Canceled}, <-done)
+	require.Equal(t, &Error{StatusCode: http.StatusUnauthorized}, <-waiter, "the waiter refresh by own context")
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
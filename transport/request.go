@@ -0,0 +1,144 @@
+package transport
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// CloneRequest copy request with own header so the middleware can change it without changes of the request
+// the body and other fields shared with the request
+func CloneRequest(r *http.Request) *http.Request {
+	c := r.WithContext(r.Context())
+	c.Header = CloneHeader(r.Header)
+
+	return c
+}
+
+// CloneHeader deep copy of the header
+func CloneHeader(h http.Header) http.Header {
+	if h == nil {
+		return make(http.Header)
+	}
+	var n int
+	for _, v := range h {
+		n += len(v)
+	}
+	values := make([]string, n)
+	c := make(http.Header, len(h))
+	for k, v := range h {
+		n = copy(values, v)
+		c[k] = values[:n:n]
+		values = values[n:]
+	}
+
+	return c
+}
+
+// Rewind set GetBody of the request which body can't be replayed by SetBody
+func Rewind(r *http.Request) error {
+	if r.Body == nil || r.Body == http.NoBody || r.GetBody != nil {
+		return nil
+	}
+
+	return SetBody(r, r.Body)
+}
+
+type readSeekerAt interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+// SetBody set body of the request which can be replayed by GetBody, each GetBody get own copy of the body
+// the body as io.ReaderAt with io.Seeker like file read by sections from the current offset and is not closed,
+// the caller owns it and must close it after the response, other bodies read to the memory and closed
+func SetBody(r *http.Request, body io.Reader) error {
+	if rs, ok := body.(readSeekerAt); ok {
+		return setSection(r, rs)
+	}
+
+	b, err := ioutil.ReadAll(body)
+	if c, ok := body.(io.Closer); ok {
+		_ = c.Close()
+	}
+	if err != nil {
+		return err
+	}
+	r.ContentLength = int64(len(b))
+	r.GetBody = func() (io.ReadCloser, error) {
+		if len(b) == 0 {
+			return http.NoBody, nil
+		}
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	r.Body, err = r.GetBody()
+
+	return err
+}
+
+func setSection(r *http.Request, body readSeekerAt) error {
+	start, err := body.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	end, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err = body.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+
+	r.ContentLength = end - start
+	r.GetBody = func() (io.ReadCloser, error) {
+		if end == start {
+			return http.NoBody, nil
+		}
+		return ioutil.NopCloser(io.NewSectionReader(body, start, end-start)), nil
+	}
+	r.Body, err = r.GetBody()
+
+	return err
+}
+
+// CopyBody copy body of the request to the writer like hash, the body read by the copy of GetBody
+// and can be sent after, the body without GetBody rewound before the copy
+func CopyBody(w io.Writer, r *http.Request) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	if err := Rewind(r); err != nil {
+		return err
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(w, body)
+
+	return err
+}
+
+// Replayable check the request can be sent again, the request without body or with GetBody
+func Replayable(r *http.Request) bool {
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+// Replay copy request with the new body by GetBody to send it again
+func Replay(r *http.Request) (*http.Request, error) {
+	req := r.WithContext(r.Context())
+	if r.Body == nil || r.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = body
+
+	return req, nil
+}
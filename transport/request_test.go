@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testReader struct {
+	*strings.Reader
+}
+
+func TestCloneRequest(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.Nil(t, err)
+	r.Header["X-Values"] = make([]string, 1, 2)
+
+	c := CloneRequest(r)
+	c.Header.Add("X-Values", "two")
+	c.Header.Set("Authorization", "token")
+	require.Equal(t, http.Header{"X-Values": {""}}, r.Header)
+	require.Equal(t, http.Header{"X-Values": {"", "two"}, "Authorization": {"token"}}, c.Header)
+	require.Equal(t, http.Header{}, CloneHeader(nil))
+}
+
+func TestCopyBody(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "/", testReader{strings.NewReader("some data")})
+	require.Nil(t, err)
+	require.Nil(t, r.GetBody)
+
+	h := sha256.New()
+	require.Nil(t, CopyBody(h, r))
+	sum := sha256.Sum256([]byte("some data"))
+	require.Equal(t, sum[:], h.Sum(nil))
+	require.Equal(t, int64(9), r.ContentLength)
+	require.NotNil(t, r.GetBody)
+
+	b, err := ioutil.ReadAll(r.Body)
+	require.Nil(t, err)
+	require.Equal(t, "some data", string(b))
+
+	r, err = http.NewRequest(http.MethodPost, "/", testReader{strings.NewReader("")})
+	require.Nil(t, err)
+	require.Nil(t, CopyBody(h, r))
+	require.Equal(t, http.NoBody, r.Body)
+}
+
+func TestReplay(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "/", strings.NewReader("some data"))
+	require.Nil(t, err)
+	require.True(t, Replayable(r))
+
+	for i := 0; i < 2; i++ {
+		replay, err := Replay(r)
+		require.Nil(t, err)
+		b, err := ioutil.ReadAll(replay.Body)
+		require.Nil(t, err)
+		require.Equal(t, "some data", string(b))
+	}
+
+	r, err = http.NewRequest(http.MethodPost, "/", testReader{strings.NewReader("some data")})
+	require.Nil(t, err)
+	require.False(t, Replayable(r))
+	r.Body = http.NoBody
+	require.True(t, Replayable(r))
+	replay, err := Replay(r)
+	require.Nil(t, err)
+	require.Equal(t, http.NoBody, replay.Body)
+}
+
+func TestSetBody(t *testing.T) {
+	f, err := ioutil.TempFile("", "body")
+	require.Nil(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	_, err = f.WriteString("skip some data")
+	require.Nil(t, err)
+	_, err = f.Seek(5, io.SeekStart)
+	require.Nil(t, err)
+
+	r, err := http.NewRequest(http.MethodPost, "/", nil)
+	require.Nil(t, err)
+	require.Nil(t, SetBody(r, f))
+	require.Equal(t, int64(9), r.ContentLength)
+
+	copies := make([]io.ReadCloser, 2)
+	for i := range copies {
+		copies[i], err = r.GetBody()
+		require.Nil(t, err)
+	}
+	for _, c := range copies {
+		b, err := ioutil.ReadAll(c)
+		require.Nil(t, err)
+		require.Equal(t, "some data", string(b))
+	}
+	b, err := ioutil.ReadAll(r.Body)
+	require.Nil(t, err)
+	require.Equal(t, "some data", string(b))
+	require.Nil(t, r.Body.Close())
+	_, err = f.Stat()
+	require.Nil(t, err, "the file is not closed by the body")
+}
@@ -0,0 +1,15 @@
+package transport
+
+import (
+	"io"
+	"io/ioutil"
+)
+
+// Drain discard the rest of the response body up to 4KB and close it so the connection can be reused
+func Drain(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(body, 4<<10))
+	_ = body.Close()
+}
@@ -0,0 +1,43 @@
+package retry
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+type attemptKey struct{}
+
+// Attempt get number of the attempt from the request context, zero when the request not passed by retry
+func Attempt(ctx context.Context) uint {
+	if a, ok := ctx.Value(attemptKey{}).(uint); ok {
+		return a
+	}
+
+	return 0
+}
+
+// Attempts get number of the attempts made to get response
+// nolint: bodyclose
+func Attempts(res *http.Response) uint {
+	if res == nil || res.Request == nil {
+		return 0
+	}
+
+	return Attempt(res.Request.Context())
+}
+
+func withAttempt(r *http.Request, attempt uint) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), attemptKey{}, attempt))
+}
+
+// rewind copy request of the attempt with new body by GetBody
+func rewind(r *http.Request, attempt uint) (*http.Request, error) {
+	req, err := transport.Replay(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return withAttempt(req, attempt), nil
+}
@@ -0,0 +1,105 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// Budget limit retries by the ratio of the requests
+// share one budget for all requests of the client
+type Budget struct {
+	mu       sync.Mutex
+	ratio    int64
+	minRetry int64
+	ttl      time.Duration
+	now      func() time.Time
+	deposits []deposit
+	balance  int64
+}
+
+// scale of the budget balance, one retry costs scale
+const scale = 1000
+
+type deposit struct {
+	at     time.Time
+	amount int64
+}
+
+// BudgetOption configure budget
+type BudgetOption func(b *Budget)
+
+// WithBudgetTTL set window of the deposits, by default 10 seconds
+func WithBudgetTTL(ttl time.Duration) BudgetOption {
+	return func(b *Budget) {
+		b.ttl = ttl
+	}
+}
+
+// WithMinRetriesPerSecond allow retries per second whatever the ratio, by default 10
+func WithMinRetriesPerSecond(retries uint) BudgetOption {
+	return func(b *Budget) {
+		b.minRetry = int64(retries)
+	}
+}
+
+// NewBudget create budget which allow retries as ratio of requests, 0.1 allow 10% retries
+func NewBudget(ratio float64, opts ...BudgetOption) *Budget {
+	b := &Budget{
+		ratio:    int64(ratio * scale),
+		minRetry: 10,
+		ttl:      time.Second * 10,
+		now:      time.Now,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+
+	return b
+}
+
+// Deposit add request to the budget
+func (b *Budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.add(b.now(), b.ratio)
+}
+
+// Withdraw take retry from the budget, return false when budget exhausted
+func (b *Budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.expire(now)
+	if b.balance+b.reserve() < scale {
+		return false
+	}
+	b.add(now, -scale)
+
+	return true
+}
+
+func (b *Budget) reserve() int64 {
+	return b.minRetry * scale * int64(b.ttl) / int64(time.Second)
+}
+
+func (b *Budget) add(now time.Time, amount int64) {
+	b.expire(now)
+	b.balance += amount
+	if l := len(b.deposits); l > 0 && now.Sub(b.deposits[l-1].at) < b.ttl/10 {
+		b.deposits[l-1].amount += amount
+		return
+	}
+	b.deposits = append(b.deposits, deposit{at: now, amount: amount})
+}
+
+func (b *Budget) expire(now time.Time) {
+	var i int
+	for ; i < len(b.deposits) && now.Sub(b.deposits[i].at) >= b.ttl; i++ {
+		b.balance -= b.deposits[i].amount
+	}
+	if i > 0 {
+		b.deposits = append(b.deposits[:0], b.deposits[i:]...)
+	}
+}
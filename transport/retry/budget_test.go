@@ -0,0 +1,92 @@
+package retry
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleWithBudget() {
+	budget := NewBudget(0.1)
+	mw := New(3, WithBackOffExponential(time.Millisecond*50), WithBudget(budget))
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, mw),
+	}
+	r, err := cl.Get("http://google.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+func TestBudget(t *testing.T) {
+	now := time.Now()
+	b := NewBudget(0.5, WithMinRetriesPerSecond(0), WithBudgetTTL(time.Second))
+	b.now = func() time.Time {
+		return now
+	}
+
+	require.False(t, b.Withdraw())
+	for i := 0; i < 4; i++ {
+		b.Deposit()
+	}
+	require.True(t, b.Withdraw())
+	require.True(t, b.Withdraw())
+	require.False(t, b.Withdraw())
+
+	now = now.Add(time.Millisecond * 500)
+	b.Deposit()
+	b.Deposit()
+	require.True(t, b.Withdraw())
+	require.False(t, b.Withdraw())
+
+	now = now.Add(time.Millisecond * 600)
+	require.False(t, b.Withdraw())
+	require.Len(t, b.deposits, 1)
+}
+
+func TestWithMinRetriesPerSecond(t *testing.T) {
+	b := NewBudget(0, WithMinRetriesPerSecond(1), WithBudgetTTL(time.Second*2))
+	require.True(t, b.Withdraw())
+	require.True(t, b.Withdraw())
+	require.False(t, b.Withdraw())
+}
+
+// nolint: bodyclose
+func TestWithBudget(t *testing.T) {
+	b := NewBudget(0.1, WithMinRetriesPerSecond(0))
+	r := New(3, WithBackOffLinear(time.Millisecond), WithBudget(b))
+	var cnt int
+	for i := 0; i < 20; i++ {
+		_, e := r(testRequest(), func(*http.Request) (*http.Response, error) {
+			cnt++
+			return nil, errResp
+		})
+		requireErrResp(t, e)
+	}
+	require.Equal(t, 22, cnt)
+}
+
+// nolint: bodyclose
+func TestWithBudget_CanceledContext(t *testing.T) {
+	b := NewBudget(0, WithMinRetriesPerSecond(1), WithBudgetTTL(time.Second))
+	r := New(3, WithBackOffLinear(time.Millisecond), WithBudget(b))
+	ctx, cancel := context.WithCancel(context.Background())
+	var cnt int
+	_, e := r(testRequest().WithContext(ctx), func(*http.Request) (*http.Response, error) {
+		cnt++
+		cancel()
+		return nil, errResp
+	})
+	requireErrResp(t, e)
+	require.Equal(t, 1, cnt)
+	require.True(t, b.Withdraw())
+	require.False(t, b.Withdraw())
+}
@@ -0,0 +1,62 @@
+package retry
+
+import (
+	"context"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+)
+
+// IsTemporary check error is temporary and request can be retried
+// retry on connection reset, refused, timeouts and unexpected EOF
+// never on cancellation, certificate and dns not found errors
+func IsTemporary(err error) bool {
+	for ; err != nil; err = unwrap(err) {
+		switch e := err.(type) {
+		case x509.CertificateInvalidError, x509.HostnameError, x509.UnknownAuthorityError,
+			x509.SystemRootsError, x509.ConstraintViolationError, x509.InsecureAlgorithmError:
+			return false
+		case *x509.CertificateInvalidError, *x509.HostnameError, *x509.UnknownAuthorityError,
+			*x509.SystemRootsError, *x509.ConstraintViolationError:
+			return false
+		case *net.DNSError:
+			return e.Timeout() || e.Temporary()
+		case syscall.Errno:
+			switch e {
+			case syscall.ECONNRESET, syscall.ECONNABORTED, syscall.ECONNREFUSED, syscall.EPIPE:
+				return true
+			}
+		}
+
+		switch err {
+		case context.Canceled:
+			return false
+		case io.ErrUnexpectedEOF, io.EOF:
+			return true
+		}
+
+		if t, ok := err.(interface{ Timeout() bool }); ok && t.Timeout() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func unwrap(err error) error {
+	switch e := err.(type) {
+	case *url.Error:
+		return e.Err
+	case *net.OpError:
+		return e.Err
+	case *os.SyscallError:
+		return e.Err
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	}
+
+	return nil
+}
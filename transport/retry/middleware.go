@@ -1,15 +1,65 @@
 package retry
 
 import (
+	"math/rand"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-4devs/httpclient/transport"
 )
 
+const maxDuration = time.Duration(1<<63 - 1)
+
 type config struct {
-	backOff        func(uint) time.Duration
+	backOff        func() func(uint) time.Duration
+	maxBackOff     time.Duration
+	maxElapsedTime time.Duration
+	retryAfter     bool
+	methods        map[string]bool
+	idempotencyKey string
+	onRetry        []func(r *http.Request, attempt uint, res *http.Response, err error)
 	checkRetriable []func(res *http.Response) bool
+	checkError     []func(err error) bool
+	budget         *Budget
+}
+
+func (c *config) isRetriableError(err error) bool {
+	for _, f := range c.checkError {
+		if f(err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canRetry check request is idempotent and body can be rewound
+func (c *config) canRetry(r *http.Request) bool {
+	if !transport.Replayable(r) {
+		return false
+	}
+
+	return c.methods[r.Method] || (c.idempotencyKey != "" && r.Header.Get(c.idempotencyKey) != "")
+}
+
+// delay get delay before the next attempt, false when the server asks to wait longer than the max back off
+func (c *config) delay(backOff func(uint) time.Duration, do uint, res *http.Response) (time.Duration, bool) {
+	if c.retryAfter {
+		if d, ok := RetryAfter(res); ok {
+			max := c.maxBackOff
+			if max <= 0 {
+				max = defaultMaxRetryAfter
+			}
+			return d, d <= max
+		}
+	}
+	d := backOff(do)
+	if c.maxBackOff > 0 && d > c.maxBackOff {
+		return c.maxBackOff, true
+	}
+
+	return d, true
 }
 
 func (c *config) isRetriable(res *http.Response) bool {
@@ -50,7 +100,9 @@ func WithStatusCode(codes ...int) Option {
 // WithBackOff configure BackOff
 func WithBackOff(fn func(uint) time.Duration) Option {
 	return func(c *config) {
-		c.backOff = fn
+		c.backOff = func() func(uint) time.Duration {
+			return fn
+		}
 	}
 }
 
@@ -61,6 +113,109 @@ func WithBackOffLinear(timeout time.Duration) Option {
 	})
 }
 
+// WithBackOffExponential set exponential back off base*2^attempt
+func WithBackOffExponential(base time.Duration) Option {
+	return WithBackOff(func(do uint) time.Duration {
+		if do > 62 || base > maxDuration>>do {
+			return maxDuration
+		}
+		return base << do
+	})
+}
+
+// WithBackOffDecorrelatedJitter set decorrelated jitter back off
+// each delay random between base and three times of the previous delay but not more max
+func WithBackOffDecorrelatedJitter(base, max time.Duration) Option {
+	return func(c *config) {
+		c.backOff = func() func(uint) time.Duration {
+			prev := base
+			return func(uint) time.Duration {
+				upper := int64(prev) * 3
+				if upper <= int64(base) || upper > int64(max) {
+					upper = int64(max)
+				}
+				prev = base
+				if upper > int64(base) {
+					prev += time.Duration(rand.Int63n(upper - int64(base)))
+				}
+				return prev
+			}
+		}
+	}
+}
+
+// WithMaxBackOff cap the delay between attempts
+func WithMaxBackOff(max time.Duration) Option {
+	return func(c *config) {
+		c.maxBackOff = max
+	}
+}
+
+// WithMaxElapsedTime stop retries when the next attempt begins after the timeout since the first attempt
+func WithMaxElapsedTime(timeout time.Duration) Option {
+	return func(c *config) {
+		c.maxElapsedTime = timeout
+	}
+}
+
+// defaultMaxRetryAfter the longest Retry-After to wait when the max back off is not set
+const defaultMaxRetryAfter = time.Minute
+
+// WithRetryAfter wait by the Retry-After header of responses with codes 429 and 503 instead of back off
+// the response with Retry-After longer than the max back off, by default one minute, returned without retries
+func WithRetryAfter() Option {
+	return func(c *config) {
+		c.retryAfter = true
+	}
+}
+
+// RetryAfter get delay by the Retry-After header in seconds or http date
+// nolint: bodyclose
+func RetryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil || (res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	val := res.Header.Get("Retry-After")
+	if val == "" {
+		return 0, false
+	}
+	if sec, err := strconv.ParseUint(val, 10, 32); err == nil {
+		return time.Duration(sec) * time.Second, true
+	}
+	if date, err := http.ParseTime(val); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// WithMethods allow retry requests with methods, by default only idempotent methods
+func WithMethods(methods ...string) Option {
+	return func(c *config) {
+		for _, m := range methods {
+			c.methods[m] = true
+		}
+	}
+}
+
+// WithIdempotencyKey set header which allow retry any method when present, by default Idempotency-Key
+func WithIdempotencyKey(header string) Option {
+	return func(c *config) {
+		c.idempotencyKey = header
+	}
+}
+
+// WithOnRetry add callback before each retry with the number of the next attempt
+// nolint: bodyclose
+func WithOnRetry(fn ...func(r *http.Request, attempt uint, res *http.Response, err error)) Option {
+	return func(c *config) {
+		c.onRetry = append(c.onRetry, fn...)
+	}
+}
+
 // WithRetriable check response to retry
 // nolint: bodyclose
 func WithRetriable(hr ...func(*http.Response) bool) Option {
@@ -69,9 +224,34 @@ func WithRetriable(hr ...func(*http.Response) bool) Option {
 	}
 }
 
+// WithRetriableError check error to retry, by default IsTemporary
+func WithRetriableError(fn ...func(err error) bool) Option {
+	return func(c *config) {
+		c.checkError = append(c.checkError, fn...)
+	}
+}
+
+// WithBudget limit retries by the budget, share it between middlewares of the client
+func WithBudget(b *Budget) Option {
+	return func(c *config) {
+		c.budget = b
+	}
+}
+
 // New create new retry middleware
+// the body of the request rewound by GetBody on each attempt, the request without GetBody sent once
 func New(retry uint, opts ...Option) transport.Middleware {
-	cfg := &config{}
+	cfg := &config{
+		methods: map[string]bool{
+			http.MethodGet:     true,
+			http.MethodHead:    true,
+			http.MethodOptions: true,
+			http.MethodTrace:   true,
+			http.MethodPut:     true,
+			http.MethodDelete:  true,
+		},
+		idempotencyKey: "Idempotency-Key",
+	}
 	WithBackOffLinear(time.Millisecond * 20)(cfg)
 
 	for _, o := range opts {
@@ -82,23 +262,52 @@ func New(retry uint, opts ...Option) transport.Middleware {
 		WithStatusCode5XX()(cfg)
 	}
 
+	if len(cfg.checkError) == 0 {
+		WithRetriableError(IsTemporary)(cfg)
+	}
+
 	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
 		var do uint
-		res, err := n(r)
-		for retry > do && (err != nil || cfg.isRetriable(res)) {
+		start := time.Now()
+		backOff := cfg.backOff()
+		if cfg.budget != nil {
+			cfg.budget.Deposit()
+		}
+		res, err := n(withAttempt(r, 1))
+		for retry > do && ((err != nil && cfg.isRetriableError(err)) || (err == nil && cfg.isRetriable(res))) && cfg.canRetry(r) {
+			delay, ok := cfg.delay(backOff, do, res)
+			if !ok {
+				return res, err
+			}
+			if cfg.maxElapsedTime > 0 && time.Since(start)+delay > cfg.maxElapsedTime {
+				return res, err
+			}
+			if r.Context().Err() != nil {
+				return res, err
+			}
+			if cfg.budget != nil && !cfg.budget.Withdraw() {
+				return res, err
+			}
+			req, rerr := rewind(r, do+2)
+			if rerr != nil {
+				return res, err
+			}
 			select {
 			case <-r.Context().Done():
+				if req.Body != nil {
+					_ = req.Body.Close()
+				}
 				return res, err
-			case <-time.After(cfg.backOff(do)):
+			case <-time.After(delay):
+				for _, f := range cfg.onRetry {
+					f(req, do+2, res, err)
+				}
 				if res != nil {
 					_ = res.Body.Close()
 				}
 			}
 			do++
-			res, err = n(r)
-			if err != nil {
-				continue
-			}
+			res, err = n(req)
 		}
 
 		return res, err
@@ -3,10 +3,16 @@ package retry
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"errors"
+	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"syscall"
 	"testing"
 	"time"
 
@@ -35,7 +41,17 @@ func ExampleNew() {
 	log.Print(r)
 }
 
-var errResp = errors.New("failed get response")
+type testError string
+
+func (e testError) Error() string {
+	return string(e)
+}
+
+func (e testError) Timeout() bool {
+	return true
+}
+
+var errResp error = testError("failed get response")
 
 func testRequest() *http.Request {
 	req, _ := http.NewRequest(http.MethodGet, "http://google.com", nil)
@@ -121,3 +137,247 @@ func TestWithRetriable(t *testing.T) {
 	require.Nil(t, d)
 	require.Equal(t, 1, cnt)
 }
+
+func TestWithBackOffExponential(t *testing.T) {
+	cfg := &config{}
+	WithBackOffExponential(time.Millisecond * 10)(cfg)
+	backOff := cfg.backOff()
+	require.Equal(t, time.Millisecond*10, backOff(0))
+	require.Equal(t, time.Millisecond*20, backOff(1))
+	require.Equal(t, time.Millisecond*80, backOff(3))
+	require.Equal(t, maxDuration, backOff(100))
+
+	WithMaxBackOff(time.Millisecond * 50)(cfg)
+	d, ok := cfg.delay(backOff, 2, nil)
+	require.True(t, ok)
+	require.Equal(t, time.Millisecond*40, d)
+	d, ok = cfg.delay(backOff, 10, nil)
+	require.True(t, ok)
+	require.Equal(t, time.Millisecond*50, d)
+}
+
+func TestWithBackOffDecorrelatedJitter(t *testing.T) {
+	cfg := &config{}
+	WithBackOffDecorrelatedJitter(time.Millisecond*10, time.Millisecond*100)(cfg)
+	backOff := cfg.backOff()
+	prev := time.Millisecond * 10
+	for i := uint(0); i < 20; i++ {
+		d := backOff(i)
+		require.True(t, d >= time.Millisecond*10)
+		require.True(t, d <= time.Millisecond*100)
+		require.True(t, d <= prev*3)
+		prev = d
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	res := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{},
+	}
+	_, ok := RetryAfter(res)
+	require.False(t, ok)
+
+	res.Header.Set("Retry-After", "120")
+	d, ok := RetryAfter(res)
+	require.True(t, ok)
+	require.Equal(t, time.Minute*2, d)
+
+	res.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	d, ok = RetryAfter(res)
+	require.True(t, ok)
+	require.True(t, d > time.Second*55 && d <= time.Minute)
+
+	res.Header.Set("Retry-After", "Wed, 21 Oct 2015 07:28:00 GMT")
+	d, ok = RetryAfter(res)
+	require.True(t, ok)
+	require.Equal(t, time.Duration(0), d)
+
+	res.Header.Set("Retry-After", "invalid")
+	_, ok = RetryAfter(res)
+	require.False(t, ok)
+
+	res.StatusCode = http.StatusInternalServerError
+	res.Header.Set("Retry-After", "1")
+	_, ok = RetryAfter(res)
+	require.False(t, ok)
+}
+
+// nolint: bodyclose
+func TestWithRetryAfter(t *testing.T) {
+	r := New(1,
+		WithBackOffLinear(time.Second*10),
+		WithStatusCode(http.StatusServiceUnavailable),
+		WithRetryAfter(),
+	)
+	var cnt int
+	start := time.Now()
+	d, e := r(testRequest(), func(*http.Request) (*http.Response, error) {
+		cnt++
+		res := &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(&bytes.Buffer{}),
+		}
+		res.Header.Set("Retry-After", "0")
+		return res, nil
+	})
+	require.Nil(t, e)
+	require.Equal(t, http.StatusServiceUnavailable, d.StatusCode)
+	require.Equal(t, 2, cnt)
+	require.True(t, time.Since(start) < time.Second)
+}
+
+// nolint: bodyclose
+func TestWithRetryAfter_MaxBackOff(t *testing.T) {
+	next := func(cnt *int, retryAfter string) func(*http.Request) (*http.Response, error) {
+		return func(*http.Request) (*http.Response, error) {
+			*cnt++
+			res := &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {retryAfter}},
+				Body:       ioutil.NopCloser(&bytes.Buffer{}),
+			}
+			return res, nil
+		}
+	}
+	codes := WithStatusCode(http.StatusTooManyRequests)
+
+	var cnt int
+	start := time.Now()
+	d, e := New(3, codes, WithRetryAfter(), WithMaxBackOff(time.Second))(testRequest(), next(&cnt, "2"))
+	require.Nil(t, e)
+	require.Equal(t, http.StatusTooManyRequests, d.StatusCode)
+	require.Equal(t, 1, cnt)
+
+	cnt = 0
+	d, e = New(3, codes, WithRetryAfter())(testRequest(), next(&cnt, "4294967295"))
+	require.Nil(t, e)
+	require.Equal(t, http.StatusTooManyRequests, d.StatusCode)
+	require.Equal(t, 1, cnt)
+	require.True(t, time.Since(start) < time.Second)
+
+	cnt = 0
+	_, e = New(1, codes, WithRetryAfter(), WithMaxBackOff(time.Second))(testRequest(), next(&cnt, "0"))
+	require.Nil(t, e)
+	require.Equal(t, 2, cnt)
+}
+
+// nolint: bodyclose
+func TestWithMaxElapsedTime(t *testing.T) {
+	r := New(100, WithBackOffLinear(time.Millisecond*40), WithMaxElapsedTime(time.Millisecond*100))
+	var cnt int
+	start := time.Now()
+	d, e := r(testRequest(), func(*http.Request) (*http.Response, error) {
+		cnt++
+		return nil, errResp
+	})
+	require.Nil(t, d)
+	requireErrResp(t, e)
+	require.Equal(t, 3, cnt)
+	require.True(t, time.Since(start) < time.Millisecond*100)
+}
+
+// nolint: bodyclose
+func TestNew_Body(t *testing.T) {
+	var (
+		attempts []uint
+		bodies   []string
+	)
+	r := New(2,
+		WithBackOffLinear(time.Millisecond),
+		WithOnRetry(func(r *http.Request, attempt uint, res *http.Response, err error) {
+			attempts = append(attempts, attempt)
+		}),
+	)
+	next := func(req *http.Request) (*http.Response, error) {
+		b, err := ioutil.ReadAll(req.Body)
+		require.Nil(t, err)
+		bodies = append(bodies, string(b))
+		return &http.Response{
+			StatusCode: http.StatusBadGateway,
+			Body:       ioutil.NopCloser(&bytes.Buffer{}),
+			Request:    req,
+		}, nil
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, "http://google.com", bytes.NewBufferString("body"))
+	d, e := r(req, next)
+	require.Nil(t, e)
+	require.Equal(t, uint(3), Attempts(d))
+	require.Equal(t, []uint{2, 3}, attempts)
+	require.Equal(t, []string{"body", "body", "body"}, bodies)
+
+	bodies = nil
+	req, _ = http.NewRequest(http.MethodPost, "http://google.com", bytes.NewBufferString("post"))
+	d, e = r(req, next)
+	require.Nil(t, e)
+	require.Equal(t, uint(1), Attempts(d))
+	require.Equal(t, []string{"post"}, bodies)
+
+	bodies = nil
+	req, _ = http.NewRequest(http.MethodPost, "http://google.com", bytes.NewBufferString("post"))
+	req.Header.Set("Idempotency-Key", "key")
+	d, e = r(req, next)
+	require.Nil(t, e)
+	require.Equal(t, uint(3), Attempts(d))
+	require.Equal(t, []string{"post", "post", "post"}, bodies)
+
+	bodies = nil
+	req, _ = http.NewRequest(http.MethodPut, "http://google.com", ioutil.NopCloser(bytes.NewBufferString("put")))
+	d, e = r(req, next)
+	require.Nil(t, e)
+	require.Equal(t, uint(1), Attempts(d))
+	require.Equal(t, []string{"put"}, bodies)
+}
+
+// nolint: bodyclose
+func TestWithMethods(t *testing.T) {
+	r := New(1, WithBackOffLinear(time.Millisecond), WithMethods(http.MethodPost))
+	var cnt int
+	req, _ := http.NewRequest(http.MethodPost, "http://google.com", nil)
+	_, e := r(req, func(*http.Request) (*http.Response, error) {
+		cnt++
+		return nil, errResp
+	})
+	requireErrResp(t, e)
+	require.Equal(t, 2, cnt)
+}
+
+func TestIsTemporary(t *testing.T) {
+	require.True(t, IsTemporary(errResp))
+	require.True(t, IsTemporary(io.ErrUnexpectedEOF))
+	require.True(t, IsTemporary(&url.Error{Op: "Get", URL: "/", Err: &net.OpError{
+		Op:  "read",
+		Net: "tcp",
+		Err: os.NewSyscallError("read", syscall.ECONNRESET),
+	}}))
+	require.True(t, IsTemporary(&url.Error{Op: "Get", URL: "/", Err: context.DeadlineExceeded}))
+
+	require.False(t, IsTemporary(errors.New("some error")))
+	require.False(t, IsTemporary(&url.Error{Op: "Get", URL: "/", Err: context.Canceled}))
+	require.False(t, IsTemporary(&url.Error{Op: "Get", URL: "/", Err: x509.UnknownAuthorityError{}}))
+	require.False(t, IsTemporary(&url.Error{Op: "Get", URL: "/", Err: &net.OpError{
+		Op:  "dial",
+		Net: "tcp",
+		Err: &net.DNSError{Err: "no such host", Name: "example.invalid"},
+	}}))
+}
+
+// nolint: bodyclose
+func TestWithRetriableError(t *testing.T) {
+	errPermanent := errors.New("permanent")
+	r := New(3, WithBackOffLinear(time.Millisecond), WithRetriableError(func(err error) bool {
+		return err != errPermanent
+	}))
+	var cnt int
+	_, e := r(testRequest(), func(*http.Request) (*http.Response, error) {
+		cnt++
+		if cnt == 2 {
+			return nil, errPermanent
+		}
+		return nil, errors.New("temporary")
+	})
+	require.Equal(t, errPermanent, e)
+	require.Equal(t, 2, cnt)
+}
@@ -0,0 +1,71 @@
+// Package sigv4 sign requests by the AWS signature version 4
+package sigv4
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+type config struct {
+	unsigned      bool
+	contentHeader bool
+	escapePath    bool
+	now           func() time.Time
+}
+
+// Option configure signer
+type Option func(c *config)
+
+// WithUnsignedPayload sign requests without hashing the payload for the streaming bodies
+func WithUnsignedPayload() Option {
+	return func(c *config) {
+		c.unsigned = true
+	}
+}
+
+// WithContentSHA256Header set hash of the payload to the x-amz-content-sha256 header, by default only for s3
+func WithContentSHA256Header() Option {
+	return func(c *config) {
+		c.contentHeader = true
+	}
+}
+
+// WithDisablePathEscaping sign path escaped once as s3 does
+func WithDisablePathEscaping() Option {
+	return func(c *config) {
+		c.escapePath = false
+	}
+}
+
+// WithNow set time of the signature, by default time.Now
+func WithNow(now func() time.Time) Option {
+	return func(c *config) {
+		c.now = now
+	}
+}
+
+// New create middleware which sign requests by the credentials
+// the signing middleware must be the last which changes headers of the request
+func New(creds CredentialsProvider, region, service string, opts ...Option) transport.Middleware {
+	s := NewSigner(region, service, opts...)
+	now := s.cfg.now
+	if now == nil {
+		now = time.Now
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		c, err := creds.Retrieve(r.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		sr := transport.CloneRequest(r)
+		if err := s.Sign(sr, c, now()); err != nil {
+			return nil, err
+		}
+
+		return n(sr)
+	}
+}
@@ -0,0 +1,292 @@
+package sigv4
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+// Algorithm of the signature
+const Algorithm = "AWS4-HMAC-SHA256"
+
+// UnsignedPayload hash of the payload which not signed
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// EmptyPayload hash of the empty payload
+const EmptyPayload = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+const (
+	timeFormat  = "20060102T150405Z"
+	shortFormat = "20060102"
+)
+
+// ErrExpires presigned url expires must be between one second and seven days
+var ErrExpires = errors.New("sigv4: expires must be between one second and seven days")
+
+// Credentials of the signature
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Retrieve static credentials
+func (c Credentials) Retrieve(ctx context.Context) (Credentials, error) {
+	return c, nil
+}
+
+// CredentialsProvider get credentials for each signed request
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// ignoredHeaders not signed because can be changed by proxies and transport
+var ignoredHeaders = map[string]bool{
+	"Authorization":     true,
+	"User-Agent":        true,
+	"X-Amzn-Trace-Id":   true,
+	"Expect":            true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+}
+
+// NewSigner create signer of the region and service
+// the s3 service signed with the x-amz-content-sha256 header and without double escaping of the path
+func NewSigner(region, service string, opts ...Option) *Signer {
+	cfg := &config{
+		contentHeader: service == "s3",
+		escapePath:    service != "s3",
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return &Signer{
+		region:  region,
+		service: service,
+		cfg:     cfg,
+	}
+}
+
+// Signer sign requests by the AWS signature version 4
+type Signer struct {
+	region  string
+	service string
+	cfg     *config
+}
+
+// Sign request by the authorization header
+// the body without GetBody read to the memory for hashing
+func (s *Signer) Sign(r *http.Request, creds Credentials, t time.Time) error {
+	payload, err := s.payloadHash(r)
+	if err != nil {
+		return err
+	}
+
+	t = t.UTC()
+	r.Header.Set("X-Amz-Date", t.Format(timeFormat))
+	if creds.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	if s.cfg.contentHeader {
+		r.Header.Set("X-Amz-Content-Sha256", payload)
+	}
+	r.Header.Del("Authorization")
+
+	headers, signed := canonicalHeaders(r)
+	canonical := strings.Join([]string{
+		r.Method,
+		s.canonicalURI(r.URL),
+		canonicalQuery(r.URL.Query()),
+		headers,
+		signed,
+		payload,
+	}, "\n")
+
+	scope := s.scope(t)
+	r.Header.Set("Authorization", Algorithm+
+		" Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signed+
+		", Signature="+s.signature(creds, t, scope, canonical))
+
+	return nil
+}
+
+// Presign get url with the signature in the query, valid for expires duration
+func (s *Signer) Presign(r *http.Request, creds Credentials, expires time.Duration, t time.Time) (*url.URL, error) {
+	if expires < time.Second || expires > 7*24*time.Hour {
+		return nil, ErrExpires
+	}
+
+	t = t.UTC()
+	scope := s.scope(t)
+	headers, signed := canonicalHeaders(r)
+
+	u := *r.URL
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", Algorithm)
+	query.Set("X-Amz-Credential", creds.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", t.Format(timeFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", signed)
+	if creds.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	payload := EmptyPayload
+	if s.service == "s3" {
+		payload = UnsignedPayload
+	}
+	canonical := strings.Join([]string{
+		r.Method,
+		s.canonicalURI(&u),
+		canonicalQuery(query),
+		headers,
+		signed,
+		payload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(creds, t, scope, canonical))
+	u.RawQuery = canonicalQuery(query)
+
+	return &u, nil
+}
+
+func (s *Signer) scope(t time.Time) string {
+	return t.Format(shortFormat) + "/" + s.region + "/" + s.service + "/aws4_request"
+}
+
+func (s *Signer) signature(creds Credentials, t time.Time, scope, canonical string) string {
+	toSign := Algorithm + "\n" + t.Format(timeFormat) + "\n" + scope + "\n" + hashHex([]byte(canonical))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), t.Format(shortFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func (s *Signer) payloadHash(r *http.Request) (string, error) {
+	if h := r.Header.Get("X-Amz-Content-Sha256"); h != "" {
+		return h, nil
+	}
+	if s.cfg.unsigned {
+		return UnsignedPayload, nil
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return EmptyPayload, nil
+	}
+
+	h := sha256.New()
+	if err := transport.CopyBody(h, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (s *Signer) canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	if !s.cfg.escapePath {
+		if p, err := url.PathUnescape(path); err == nil {
+			path = p
+		}
+	}
+
+	return escape(path, false)
+}
+
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		name = escape(name, true)
+		for _, v := range values {
+			pairs = append(pairs, name+"="+escape(v, true))
+		}
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaders(r *http.Request) (string, string) {
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	values := map[string]string{"host": host}
+	names := []string{"host"}
+	for name, v := range r.Header {
+		if ignoredHeaders[name] {
+			continue
+		}
+		lower := strings.ToLower(name)
+		if lower == "host" {
+			continue
+		}
+		trimmed := make([]string, len(v))
+		for i := range v {
+			trimmed[i] = strings.Join(strings.Fields(v[i]), " ")
+		}
+		values[lower] = strings.Join(trimmed, ",")
+		names = append(names, lower)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(values[name])
+		b.WriteByte('\n')
+	}
+
+	return b.String(), strings.Join(names, ";")
+}
+
+// escape uri encode except unreserved characters
+func escape(s string, encodeSlash bool) string {
+	const hexUpper = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexUpper[c>>4])
+			b.WriteByte(hexUpper[c&15])
+		}
+	}
+
+	return b.String()
+}
+
+func hashHex(b []byte) string {
+	h := sha256.Sum256(b)
+
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
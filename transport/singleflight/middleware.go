@@ -0,0 +1,156 @@
+// Package singleflight collapse identical in-flight requests into one
+package singleflight
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-4devs/httpclient/transport"
+)
+
+type config struct {
+	methods map[string]bool
+	headers []string
+}
+
+// Option configure singleflight
+type Option func(c *config)
+
+// WithHeaders add headers to the key of the request, by default Authorization and Cookie
+func WithHeaders(names ...string) Option {
+	return func(c *config) {
+		for _, name := range names {
+			c.headers = append(c.headers, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// WithMethods set methods of the collapsed requests, by default GET and HEAD
+func WithMethods(methods ...string) Option {
+	return func(c *config) {
+		c.methods = make(map[string]bool, len(methods))
+		for _, m := range methods {
+			c.methods[m] = true
+		}
+	}
+}
+
+func (c *config) key(r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+	b.WriteString(r.URL.String())
+	for _, name := range c.headers {
+		b.WriteByte('\n')
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(r.Header[name], ","))
+	}
+
+	return b.String()
+}
+
+type call struct {
+	done     chan struct{}
+	res      *http.Response
+	body     []byte
+	err      error
+	canceled bool
+}
+
+// response copy shared response with own body
+func (c *call) response(r *http.Request) *http.Response {
+	res := *c.res
+	res.Header = make(http.Header, len(c.res.Header))
+	for k, v := range c.res.Header {
+		res.Header[k] = append([]string(nil), v...)
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	res.ContentLength = int64(len(c.body))
+	res.Request = r
+
+	return &res
+}
+
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// New create middleware which share response of the in-flight request with identical requests
+// requests with different credentials are not merged, the middleware which sets credentials must be before singleflight
+func New(opts ...Option) transport.Middleware {
+	cfg := &config{
+		methods: map[string]bool{
+			http.MethodGet:  true,
+			http.MethodHead: true,
+		},
+		headers: []string{"Authorization", "Cookie"},
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	g := &group{
+		calls: make(map[string]*call),
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		if !cfg.methods[r.Method] {
+			return n(r)
+		}
+
+		key := cfg.key(r)
+		g.mu.Lock()
+		if c, ok := g.calls[key]; ok {
+			g.mu.Unlock()
+			return g.wait(r, n, c)
+		}
+		c := &call{done: make(chan struct{})}
+		g.calls[key] = c
+		g.mu.Unlock()
+
+		g.do(r, n, c)
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+
+		if c.err != nil {
+			return nil, c.err
+		}
+
+		return c.response(r), nil
+	}
+}
+
+func (g *group) do(r *http.Request, n func(r *http.Request) (*http.Response, error), c *call) {
+	c.res, c.err = n(r)
+	if c.err == nil {
+		c.body, c.err = ioutil.ReadAll(c.res.Body)
+		_ = c.res.Body.Close()
+	}
+	c.canceled = c.err != nil && r.Context().Err() != nil
+}
+
+func (g *group) wait(r *http.Request, n func(r *http.Request) (*http.Response, error), c *call) (*http.Response, error) {
+	select {
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	case <-c.done:
+	}
+
+	if c.err != nil {
+		// the leader request canceled by own context, do request by the waiter context
+		if c.canceled {
+			return n(r)
+		}
+		return nil, c.err
+	}
+
+	return c.response(r), nil
+}
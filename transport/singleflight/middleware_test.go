@@ -0,0 +1,172 @@
+package singleflight
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	mw := New(WithHeaders("Accept"))
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, mw),
+	}
+	r, err := cl.Get("http://google.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Body.Close()
+	log.Print(r)
+}
+
+func testRequest(method, accept string) *http.Request {
+	req, _ := http.NewRequest(method, "http://google.com", nil)
+	req.Header.Set("Accept", accept)
+	return req
+}
+
+func TestNew(t *testing.T) {
+	var cnt int32
+	release := make(chan struct{})
+	mw := New(WithHeaders("accept"))
+	next := func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&cnt, 1)
+		<-release
+		res := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(r.Method + " " + r.Header.Get("Accept"))),
+		}
+		res.Header.Set("Content-Type", "text/plain")
+		return res, nil
+	}
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 9)
+	for i := range bodies {
+		method, accept := http.MethodGet, "application/json"
+		switch i % 3 {
+		case 1:
+			accept = "text/html"
+		case 2:
+			method = http.MethodPost
+		}
+		wg.Add(1)
+		go func(i int, req *http.Request) {
+			defer wg.Done()
+			res, err := mw(req, next)
+			require.Nil(t, err)
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Equal(t, "text/plain", res.Header.Get("Content-Type"))
+			b, err := ioutil.ReadAll(res.Body)
+			require.Nil(t, err)
+			require.Nil(t, res.Body.Close())
+			bodies[i] = string(b)
+		}(i, testRequest(method, accept))
+	}
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(5), atomic.LoadInt32(&cnt))
+	for i, b := range bodies {
+		switch i % 3 {
+		case 0:
+			require.Equal(t, "GET application/json", b)
+		case 1:
+			require.Equal(t, "GET text/html", b)
+		case 2:
+			require.Equal(t, "POST application/json", b)
+		}
+	}
+}
+
+// nolint: bodyclose
+func TestNew_Error(t *testing.T) {
+	var cnt int32
+	release := make(chan struct{})
+	mw := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	next := func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&cnt, 1)
+		select {
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		case <-release:
+		}
+		return nil, errors.New("failed")
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := mw(testRequest(http.MethodGet, "").WithContext(ctx), next)
+		errs <- err
+	}()
+	time.Sleep(time.Millisecond * 20)
+	go func() {
+		_, err := mw(testRequest(http.MethodGet, ""), next)
+		errs <- err
+	}()
+	time.Sleep(time.Millisecond * 20)
+	cancel()
+	require.Equal(t, context.Canceled, <-errs)
+	close(release)
+	require.EqualError(t, <-errs, "failed")
+	require.Equal(t, int32(2), atomic.LoadInt32(&cnt))
+}
+
+func TestNew_Credentials(t *testing.T) {
+	var cnt int32
+	release := make(chan struct{})
+	mw := New()
+	next := func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&cnt, 1)
+		<-release
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(r.Header.Get("Authorization") + r.Header.Get("Cookie"))),
+		}, nil
+	}
+
+	credentials := []http.Header{
+		{"Authorization": {"Bearer alice"}},
+		{"Authorization": {"Bearer bob"}},
+		{"Cookie": {"session=alice"}},
+		{"Cookie": {"session=bob"}},
+		{"Authorization": {"Bearer alice"}},
+	}
+	var wg sync.WaitGroup
+	bodies := make([]string, len(credentials))
+	for i, h := range credentials {
+		req := testRequest(http.MethodGet, "")
+		req.Header = h
+		wg.Add(1)
+		go func(i int, req *http.Request) {
+			defer wg.Done()
+			res, err := mw(req, next)
+			require.Nil(t, err)
+			b, err := ioutil.ReadAll(res.Body)
+			require.Nil(t, err)
+			require.Nil(t, res.Body.Close())
+			bodies[i] = string(b)
+		}(i, req)
+	}
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(4), atomic.LoadInt32(&cnt))
+	require.Equal(t, []string{"Bearer alice", "Bearer bob", "session=alice", "session=bob", "Bearer alice"}, bodies)
+}
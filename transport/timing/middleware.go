@@ -0,0 +1,129 @@
+// Package timing record timing breakdown of the requests by httptrace
+package timing
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
+)
+
+// New create timing middleware
+// the timing set to the request context and available by httpclient.TimingFrom of the response request context
+func New() transport.Middleware {
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		rec := &httpclient.TimingRecorder{}
+		start := time.Now()
+		ctx := httpclient.WithTiming(r.Context(), rec)
+		ctx = httptrace.WithClientTrace(ctx, trace(rec, start))
+
+		res, err := n(r.WithContext(ctx))
+		headers := time.Now()
+		if err != nil || res.Body == nil {
+			rec.Update(func(t *httpclient.Timing) {
+				t.Total = headers.Sub(start)
+			})
+			return res, err
+		}
+
+		res.Body = &body{
+			ReadCloser: res.Body,
+			done: func() {
+				end := time.Now()
+				rec.Update(func(t *httpclient.Timing) {
+					t.Body = end.Sub(headers)
+					t.Total = end.Sub(start)
+				})
+			},
+		}
+
+		return res, nil
+	}
+}
+
+func trace(rec *httpclient.TimingRecorder, start time.Time) *httptrace.ClientTrace {
+	var dns, connect, handshake time.Time
+	var mu sync.Mutex
+	begin := func(at *time.Time) {
+		mu.Lock()
+		*at = time.Now()
+		mu.Unlock()
+	}
+	since := func(at *time.Time) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return time.Since(*at)
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			begin(&dns)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			d := since(&dns)
+			rec.Update(func(t *httpclient.Timing) {
+				t.DNS = d
+			})
+		},
+		ConnectStart: func(network, addr string) {
+			begin(&connect)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err != nil {
+				return
+			}
+			d := since(&connect)
+			rec.Update(func(t *httpclient.Timing) {
+				t.Connect = d
+			})
+		},
+		TLSHandshakeStart: func() {
+			begin(&handshake)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			d := since(&handshake)
+			rec.Update(func(t *httpclient.Timing) {
+				t.TLS = d
+			})
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			rec.Update(func(t *httpclient.Timing) {
+				t.Reused = info.Reused
+			})
+		},
+		GotFirstResponseByte: func() {
+			d := time.Since(start)
+			rec.Update(func(t *httpclient.Timing) {
+				t.FirstByte = d
+			})
+		},
+	}
+}
+
+type body struct {
+	io.ReadCloser
+	done func()
+	once sync.Once
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.done)
+	}
+
+	return n, err
+}
+
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+
+	return err
+}
@@ -0,0 +1,84 @@
+package timing
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, New()),
+	}
+	r, err := cl.Get("https://google.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, _ = ioutil.ReadAll(r.Body)
+	_ = r.Body.Close()
+	log.Printf("%+v", httpclient.TimingFrom(r.Request.Context()).Timing())
+}
+
+func TestNew(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(srv.Client().Transport, New()),
+	}
+
+	res, err := cl.Get(srv.URL)
+	require.Nil(t, err)
+	rec := httpclient.TimingFrom(res.Request.Context())
+	require.NotNil(t, rec)
+	b, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	require.Equal(t, "ok", string(b))
+	require.Nil(t, res.Body.Close())
+
+	tm := rec.Timing()
+	require.False(t, tm.Reused)
+	require.True(t, tm.Connect > 0)
+	require.True(t, tm.TLS > 0)
+	require.True(t, tm.FirstByte > 0)
+	require.True(t, tm.Body >= 10*time.Millisecond)
+	require.True(t, tm.Total >= tm.FirstByte+tm.Body)
+	require.Equal(t, tm.DNS+tm.Connect+tm.TLS, tm.Network())
+
+	res, err = cl.Get(srv.URL)
+	require.Nil(t, err)
+	_, _ = ioutil.ReadAll(res.Body)
+	require.Nil(t, res.Body.Close())
+
+	tm = httpclient.TimingFrom(res.Request.Context()).Timing()
+	require.True(t, tm.Reused)
+	require.Equal(t, time.Duration(0), tm.Connect)
+	require.Equal(t, time.Duration(0), tm.TLS)
+}
+
+func TestNew_Error(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://google.com", nil)
+	require.Nil(t, err)
+
+	var rec *httpclient.TimingRecorder
+	_, err = New()(req, func(r *http.Request) (*http.Response, error) {
+		rec = httpclient.TimingFrom(r.Context())
+		return nil, errors.New("connection refused")
+	})
+	require.EqualError(t, err, "connection refused")
+	require.True(t, rec.Timing().Total > 0)
+	require.Equal(t, httpclient.Timing{}, httpclient.TimingFrom(req.Context()).Timing())
+}
@@ -0,0 +1,204 @@
+// Package tracing propagate span context of the request and report client spans
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
+)
+
+// Tracer start client spans of the requests
+type Tracer interface {
+	// Start span of the request, the returned context carries span context of the started span
+	Start(ctx context.Context, r *http.Request) (context.Context, Span)
+}
+
+// Span of the client request
+type Span interface {
+	SpanContext() SpanContext
+	// End span by response or error, called when response body closed
+	End(res *http.Response, err error)
+}
+
+// SpanName get name of the span by method and route template of the request
+func SpanName(r *http.Request) string {
+	if route := httpclient.Route(r.Context()); route != "" {
+		return r.Method + " " + route
+	}
+
+	return r.Method
+}
+
+// Report of the ended client span
+type Report struct {
+	SpanContext SpanContext
+	Parent      SpanID
+	Name        string
+	Start       time.Time
+	End         time.Time
+	Request     *http.Request
+	// Response of the request, nil when request failed
+	Response *http.Response
+	Err      error
+}
+
+// Duration of the span
+func (r Report) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// StatusCode of the response, zero when request failed
+func (r Report) StatusCode() int {
+	if r.Response == nil {
+		return 0
+	}
+
+	return r.Response.StatusCode
+}
+
+// NewTracer create tracer which start child spans of the span context by context
+// the ended spans reported to the func, nil report only propagate span context
+func NewTracer(report func(ctx context.Context, r Report)) Tracer {
+	return tracer(report)
+}
+
+type tracer func(ctx context.Context, r Report)
+
+func (t tracer) Start(ctx context.Context, r *http.Request) (context.Context, Span) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.TraceID.IsValid() {
+		return ctx, &span{}
+	}
+	parent := sc.SpanID
+	sc.SpanID = NewSpanID()
+	s := &span{sc: sc}
+	if t != nil {
+		start := time.Now()
+		s.end = func(res *http.Response, err error) {
+			t(ctx, Report{
+				SpanContext: sc,
+				Parent:      parent,
+				Name:        SpanName(r),
+				Start:       start,
+				End:         time.Now(),
+				Request:     r,
+				Response:    res,
+				Err:         err,
+			})
+		}
+	}
+
+	return ContextWithSpanContext(ctx, sc), s
+}
+
+type span struct {
+	sc  SpanContext
+	end func(res *http.Response, err error)
+}
+
+func (s *span) SpanContext() SpanContext {
+	return s.sc
+}
+
+func (s *span) End(res *http.Response, err error) {
+	if s.end != nil {
+		s.end(res, err)
+	}
+}
+
+type config struct {
+	tracer      Tracer
+	propagators []Propagator
+}
+
+// Option configure tracing
+type Option func(c *config)
+
+// WithTracer set tracer, by default start child spans of the span context by context
+func WithTracer(t Tracer) Option {
+	return func(c *config) {
+		c.tracer = t
+	}
+}
+
+// WithPropagators set propagators, by default TraceContext
+func WithPropagators(p ...Propagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// WithB3 add b3 propagation to the propagators
+func WithB3(single bool) Option {
+	return func(c *config) {
+		c.propagators = append(c.propagators, B3{Single: single})
+	}
+}
+
+// New create tracing middleware
+// the span ended when response body closed
+func New(opts ...Option) transport.Middleware {
+	cfg := &config{
+		tracer:      NewTracer(nil),
+		propagators: []Propagator{TraceContext{}},
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return func(r *http.Request, n func(r *http.Request) (*http.Response, error)) (*http.Response, error) {
+		ctx, s := cfg.tracer.Start(r.Context(), r)
+		if sc := s.SpanContext(); sc.IsValid() {
+			r = r.WithContext(ctx)
+			r.Header = transport.CloneHeader(r.Header)
+			for _, p := range cfg.propagators {
+				p.Inject(sc, r.Header)
+			}
+		}
+
+		res, err := n(r)
+		if err != nil || res.Body == nil {
+			s.End(res, err)
+			return res, err
+		}
+
+		res.Body = &body{
+			ReadCloser: res.Body,
+			done: func(err error) {
+				s.End(res, err)
+			},
+		}
+
+		return res, nil
+	}
+}
+
+type body struct {
+	io.ReadCloser
+	done func(err error)
+	once sync.Once
+	err  error
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+
+	return n, err
+}
+
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.done(b.err)
+	})
+
+	return err
+}
@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-4devs/httpclient"
+	"github.com/go-4devs/httpclient/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	mw := New(WithB3(true), WithTracer(NewTracer(func(ctx context.Context, r Report) {
+		log.Print(r.Name, r.SpanContext.Traceparent(), r.StatusCode(), r.Duration(), r.Err)
+	})))
+
+	cl := http.Client{
+		Transport: transport.NewMiddleware(http.DefaultTransport, mw),
+	}
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r, err := http.NewRequest(http.MethodGet, "http://google.com", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	res, err := cl.Do(r.WithContext(ContextWithSpanContext(r.Context(), parent)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer res.Body.Close()
+	log.Print(res)
+}
+
+func TestNew(t *testing.T) {
+	parent, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.Nil(t, err)
+	parent.State = "congo=t61rcWkgMzE"
+
+	var reported []Report
+	mw := New(WithB3(false), WithTracer(NewTracer(func(ctx context.Context, r Report) {
+		reported = append(reported, r)
+	})))
+
+	req, err := http.NewRequest(http.MethodGet, "http://google.com/user/1", nil)
+	require.Nil(t, err)
+	ctx := httpclient.WithRoute(ContextWithSpanContext(req.Context(), parent), "/user/%d")
+	req = req.WithContext(ctx)
+
+	var child SpanContext
+	start := time.Now()
+	res, err := mw(req, func(r *http.Request) (*http.Response, error) {
+		child = SpanContextFromContext(r.Context())
+		require.Equal(t, child.Traceparent(), r.Header.Get("Traceparent"))
+		require.Equal(t, "congo=t61rcWkgMzE", r.Header.Get("Tracestate"))
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", r.Header.Get("X-B3-TraceId"))
+		require.Equal(t, child.SpanID.String(), r.Header.Get("X-B3-SpanId"))
+		require.Equal(t, "1", r.Header.Get("X-B3-Sampled"))
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("ok")),
+		}, nil
+	})
+	require.Nil(t, err)
+	require.Empty(t, req.Header, "origin request not changed")
+	require.Equal(t, parent.TraceID, child.TraceID)
+	require.NotEqual(t, parent.SpanID, child.SpanID)
+	require.Empty(t, reported)
+
+	time.Sleep(time.Millisecond * 10)
+	require.Nil(t, res.Body.Close())
+	require.Len(t, reported, 1)
+	require.Equal(t, child, reported[0].SpanContext)
+	require.Equal(t, parent.SpanID, reported[0].Parent)
+	require.Equal(t, "GET /user/%d", reported[0].Name)
+	require.Equal(t, http.StatusOK, reported[0].StatusCode())
+	require.False(t, reported[0].Start.Before(start))
+	require.True(t, reported[0].Duration() >= time.Millisecond*10)
+}
+
+type testTracer struct {
+	ended []error
+}
+
+func (t *testTracer) Start(ctx context.Context, r *http.Request) (context.Context, Span) {
+	sc := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}}
+	return ContextWithSpanContext(ctx, sc), &span{sc: sc, end: func(res *http.Response, err error) {
+		t.ended = append(t.ended, err)
+	}}
+}
+
+func TestNew_Tracer(t *testing.T) {
+	tr := &testTracer{}
+	mw := New(WithTracer(tr), WithPropagators(B3{Single: true}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://google.com", nil)
+	require.Nil(t, err)
+	_, err = mw(req, func(r *http.Request) (*http.Response, error) {
+		require.Equal(t, "01000000000000000000000000000000-0200000000000000-0", r.Header.Get("B3"))
+		require.Empty(t, r.Header.Get("Traceparent"))
+		return nil, errors.New("connection refused")
+	})
+	require.EqualError(t, err, "connection refused")
+	require.Len(t, tr.ended, 1)
+	require.EqualError(t, tr.ended[0], "connection refused")
+}
+
+func TestNew_WithoutSpan(t *testing.T) {
+	mw := New()
+	req, err := http.NewRequest(http.MethodGet, "http://google.com", nil)
+	require.Nil(t, err)
+	_, err = mw(req, func(r *http.Request) (*http.Response, error) {
+		require.Empty(t, r.Header)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	require.Nil(t, err)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.Nil(t, err)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	require.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	require.True(t, sc.Sampled())
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	sc, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	require.Nil(t, err)
+	require.False(t, sc.Sampled())
+
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+	} {
+		_, err = ParseTraceparent(v)
+		require.Equal(t, ErrInvalidTraceparent, err, v)
+	}
+}
+
+func TestTraceContext_Extract(t *testing.T) {
+	h := http.Header{}
+	h.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.Set("Tracestate", "rojo=00f067aa0ba902b7")
+
+	sc, err := TraceContext{}.Extract(h)
+	require.Nil(t, err)
+	require.Equal(t, "rojo=00f067aa0ba902b7", sc.State)
+
+	out := http.Header{}
+	TraceContext{}.Inject(sc, out)
+	require.Equal(t, h, out)
+}
@@ -0,0 +1,27 @@
+module github.com/go-4devs/httpclient/transport/tracing/otel
+
+go 1.20
+
+replace (
+	github.com/go-4devs/httpclient => ../../../
+	github.com/go-4devs/httpclient/transport => ../../
+)
+
+require (
+	github.com/go-4devs/httpclient/transport v0.0.2
+	github.com/stretchr/testify v1.8.4
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-4devs/httpclient v0.0.2 // indirect
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,86 @@
+// Package otel report client spans of the tracing middleware by OpenTelemetry
+package otel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-4devs/httpclient/transport/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ tracing.Tracer = &Tracer{}
+
+// NewTracer create tracer by the OpenTelemetry tracer
+func NewTracer(t trace.Tracer) *Tracer {
+	return &Tracer{tracer: t}
+}
+
+// Tracer start client spans by the OpenTelemetry tracer
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// Start client span, parent is span of the context or span context of the tracing package
+func (t *Tracer) Start(ctx context.Context, r *http.Request) (context.Context, tracing.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, toOtel(sc))
+		}
+	}
+
+	ctx, s := t.tracer.Start(ctx, tracing.SpanName(r),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.full", r.URL.Redacted()),
+			attribute.String("server.address", r.URL.Hostname()),
+		),
+	)
+	sp := &span{span: s}
+
+	return tracing.ContextWithSpanContext(ctx, sp.SpanContext()), sp
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s *span) SpanContext() tracing.SpanContext {
+	sc := s.span.SpanContext()
+
+	return tracing.SpanContext{
+		TraceID: tracing.TraceID(sc.TraceID()),
+		SpanID:  tracing.SpanID(sc.SpanID()),
+		Flags:   byte(sc.TraceFlags()),
+		State:   sc.TraceState().String(),
+	}
+}
+
+func (s *span) End(res *http.Response, err error) {
+	switch {
+	case err != nil:
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	case res != nil:
+		s.span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+		if res.StatusCode >= http.StatusBadRequest {
+			s.span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+		}
+	}
+	s.span.End()
+}
+
+func toOtel(sc tracing.SpanContext) trace.SpanContext {
+	state, _ := trace.ParseTraceState(sc.State)
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID(sc.TraceID),
+		SpanID:     trace.SpanID(sc.SpanID),
+		TraceFlags: trace.TraceFlags(sc.Flags),
+		TraceState: state,
+		Remote:     true,
+	})
+}
@@ -0,0 +1,75 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/go-4devs/httpclient/transport/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	mw := tracing.New(tracing.WithTracer(NewTracer(tp.Tracer("httpclient"))))
+
+	parent, err := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.Nil(t, err)
+	req, err := http.NewRequest(http.MethodGet, "http://google.com/search?q=1", nil)
+	require.Nil(t, err)
+	req = req.WithContext(tracing.ContextWithSpanContext(req.Context(), parent))
+
+	var header string
+	res, err := mw(req, func(r *http.Request) (*http.Response, error) {
+		header = r.Header.Get("Traceparent")
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(bytes.NewBufferString("not found")),
+		}, nil
+	})
+	require.Nil(t, err)
+	require.Empty(t, rec.Ended())
+	require.Nil(t, res.Body.Close())
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	require.Equal(t, "GET", s.Name())
+	require.Equal(t, trace.SpanKindClient, s.SpanKind())
+	require.Equal(t, parent.TraceID.String(), s.Parent().TraceID().String())
+	require.Equal(t, parent.SpanID.String(), s.Parent().SpanID().String())
+	require.Equal(t, "00-"+s.SpanContext().TraceID().String()+"-"+s.SpanContext().SpanID().String()+"-01", header)
+	require.Equal(t, codes.Error, s.Status().Code)
+	require.Contains(t, s.Attributes(), attribute.Int("http.response.status_code", http.StatusNotFound))
+}
+
+func TestTracer_Error(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	tr := tp.Tracer("httpclient")
+	mw := tracing.New(tracing.WithTracer(NewTracer(tr)))
+
+	ctx, parent := tr.Start(context.Background(), "parent")
+	req, err := http.NewRequest(http.MethodPost, "http://google.com", nil)
+	require.Nil(t, err)
+	_, err = mw(req.WithContext(ctx), func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	require.EqualError(t, err, "connection refused")
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Equal(t, "connection refused", spans[0].Status().Description)
+}
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"net/http"
+)
+
+var (
+	_ Propagator = TraceContext{}
+	_ Propagator = B3{}
+)
+
+// Propagator inject span context to the headers of the request
+type Propagator interface {
+	Inject(sc SpanContext, h http.Header)
+}
+
+// TraceContext propagate span context by the W3C traceparent and tracestate headers
+type TraceContext struct{}
+
+// Inject traceparent and tracestate headers
+func (TraceContext) Inject(sc SpanContext, h http.Header) {
+	h.Set("Traceparent", sc.Traceparent())
+	if sc.State != "" {
+		h.Set("Tracestate", sc.State)
+	} else {
+		h.Del("Tracestate")
+	}
+}
+
+// Extract span context by traceparent and tracestate headers
+func (TraceContext) Extract(h http.Header) (SpanContext, error) {
+	sc, err := ParseTraceparent(h.Get("Traceparent"))
+	if err != nil {
+		return sc, err
+	}
+	sc.State = h.Get("Tracestate")
+
+	return sc, nil
+}
+
+// B3 propagate span context by the zipkin B3 headers
+type B3 struct {
+	// Single use one b3 header instead of X-B3-* headers
+	Single bool
+}
+
+// Inject b3 headers
+func (b B3) Inject(sc SpanContext, h http.Header) {
+	sampled := "0"
+	if sc.Sampled() {
+		sampled = "1"
+	}
+	if b.Single {
+		h.Set("B3", sc.TraceID.String()+"-"+sc.SpanID.String()+"-"+sampled)
+		return
+	}
+	h.Set("X-B3-Traceid", sc.TraceID.String())
+	h.Set("X-B3-Spanid", sc.SpanID.String())
+	h.Set("X-B3-Sampled", sampled)
+}
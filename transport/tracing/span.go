@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidTraceparent invalid traceparent header
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// FlagSampled trace flag of the sampled span
+const FlagSampled byte = 0x01
+
+// TraceID identifier of the trace
+type TraceID [16]byte
+
+// IsValid check trace id not zero
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// String get hex of the trace id
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID identifier of the span
+type SpanID [8]byte
+
+// IsValid check span id not zero
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// String get hex of the span id
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext propagated context of the span
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+	// State vendor specific value of the tracestate header
+	State string
+}
+
+// IsValid check trace and span id
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Sampled check sampled flag
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&FlagSampled == FlagSampled
+}
+
+// Traceparent format span context to the traceparent header
+func (sc SpanContext) Traceparent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceparent parse traceparent header
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, ErrInvalidTraceparent
+	}
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) {
+		return sc, ErrInvalidTraceparent
+	}
+	var flags [1]byte
+	if !decodeHex(flags[:], parts[3]) {
+		return sc, ErrInvalidTraceparent
+	}
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return sc, ErrInvalidTraceparent
+	}
+
+	return sc, nil
+}
+
+// decodeHex decode lower case hex with exact length
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != len(dst)*2 || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+
+	return err == nil
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext set span context to the context
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext get span context by context
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+
+	return sc
+}
+
+// NewSpanID generate random span id
+func NewSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+
+	return id
+}
+
+// NewTraceID generate random trace id
+func NewTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+
+	return id
+}